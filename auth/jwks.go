@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksRefreshInterval es cada cuánto se vuelven a descargar las llaves
+	jwksRefreshInterval = time.Hour
+	// jwksMinRefreshInterval limita las descargas por un kid desconocido, para
+	// que tokens con kids inventados no saturen al proveedor de identidad
+	jwksMinRefreshInterval = time.Minute
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// JWKS descarga y cachea las llaves RSA publicadas por el proveedor de identidad
+type JWKS struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+	// refreshing se cierra al terminar la descarga en curso; nil si no hay ninguna
+	refreshing chan struct{}
+}
+
+func NewJWKS(url string) *JWKS {
+	return &JWKS{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		keys:   map[string]*rsa.PublicKey{},
+	}
+}
+
+// Key devuelve la llave con el kid indicado; sin kid se acepta la única llave
+// publicada. Un kid desconocido refresca las llaves por si el proveedor rotó.
+// La descarga corre sin el lock: las requests con llaves conocidas no la
+// esperan y las que necesitan el refresco comparten una sola descarga.
+func (j *JWKS) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	age := time.Since(j.fetchedAt)
+	if key, ok := j.lookup(kid); ok && age < jwksRefreshInterval {
+		j.mu.Unlock()
+		return key, nil
+	}
+	if age >= jwksMinRefreshInterval {
+		done := j.refreshing
+		if done == nil {
+			done = make(chan struct{})
+			j.refreshing = done
+			j.mu.Unlock()
+			j.refresh(ctx, done)
+		} else {
+			j.mu.Unlock()
+			select {
+			case <-done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		j.mu.Lock()
+	}
+	defer j.mu.Unlock()
+
+	if key, ok := j.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// refresh descarga las llaves y las publica bajo el lock; al terminar cierra
+// done para despertar a las requests que esperaban la misma descarga
+func (j *JWKS) refresh(ctx context.Context, done chan struct{}) {
+	keys, err := j.fetch(ctx)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		// Si el proveedor falla, seguir usando las últimas llaves conocidas
+		slog.WarnContext(ctx, "failed to refresh JWKS", "url", j.url, "error", err)
+	} else {
+		j.keys = keys
+	}
+	j.fetchedAt = time.Now()
+	j.refreshing = nil
+	close(done)
+}
+
+// lookup busca la llave en el cache; el llamador debe tener j.mu
+func (j *JWKS) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS responded %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || jwk.Use == "enc" {
+			continue
+		}
+		key, err := rsaPublicKey(jwk)
+		if err != nil {
+			slog.WarnContext(ctx, "ignoring invalid JWKS key", "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func rsaPublicKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestJWKSRefreshDoesNotBlockKnownKeys comprueba que un refresco lento por un
+// kid desconocido no detiene las requests con llaves conocidas y que las
+// requests que sí lo necesitan comparten una sola descarga
+func TestJWKSRefreshDoesNotBlockKnownKeys(t *testing.T) {
+	key := testRSAKey(t)
+
+	var fetches atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// La primera descarga responde al instante; las siguientes esperan release
+		if fetches.Add(1) > 1 {
+			started <- struct{}{}
+			<-release
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{publicJWK(testKid, &key.PublicKey)}})
+	}))
+	defer server.Close()
+
+	jwks := NewJWKS(server.URL)
+	ctx := context.Background()
+	if _, err := jwks.Key(ctx, testKid); err != nil {
+		t.Fatalf("Key() error = %v", err)
+	}
+
+	// Llaves descargadas hace más que el intervalo mínimo: un kid desconocido
+	// vuelve a descargarlas, uno conocido no
+	jwks.mu.Lock()
+	jwks.fetchedAt = time.Now().Add(-2 * jwksMinRefreshInterval)
+	jwks.mu.Unlock()
+
+	const waiters = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, waiters)
+	for range waiters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := jwks.Key(ctx, "rotada")
+			errs <- err
+		}()
+	}
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the unknown kid did not trigger a refresh")
+	}
+
+	known := make(chan error, 1)
+	go func() {
+		_, err := jwks.Key(ctx, testKid)
+		known <- err
+	}()
+	select {
+	case err := <-known:
+		if err != nil {
+			t.Errorf("Key() for a known kid error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("a known kid waited for the JWKS refresh")
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("Key() for an unknown kid error = %v, want %v", err, ErrUnknownKey)
+		}
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want 2 (one refresh shared by every waiter)", got)
+	}
+}
+
+func TestJWKSKeepsKeysWhenRefreshFails(t *testing.T) {
+	key := testRSAKey(t)
+
+	var fail atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			http.Error(w, "no disponible", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{publicJWK(testKid, &key.PublicKey)}})
+	}))
+	defer server.Close()
+
+	jwks := NewJWKS(server.URL)
+	if _, err := jwks.Key(context.Background(), testKid); err != nil {
+		t.Fatalf("Key() error = %v", err)
+	}
+
+	fail.Store(true)
+	jwks.mu.Lock()
+	jwks.fetchedAt = time.Now().Add(-2 * jwksRefreshInterval)
+	jwks.mu.Unlock()
+
+	if _, err := jwks.Key(context.Background(), testKid); err != nil {
+		t.Errorf("Key() after a failed refresh error = %v, want the last known key", err)
+	}
+}
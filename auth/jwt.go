@@ -0,0 +1,224 @@
+// Package auth valida los JWT con los que los usuarios se identifican ante la
+// API. Solo usa la biblioteca estándar: HS256/384/512 con un secreto compartido
+// y RS256/384/512 con las llaves públicas de un JWKS.
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"loan-agent/clock"
+)
+
+// clockSkew es la tolerancia al validar exp y nbf entre relojes desincronizados
+const clockSkew = time.Minute
+
+var (
+	ErrMalformedToken = errors.New("malformed token")
+	ErrInvalidToken   = errors.New("invalid token signature")
+	ErrExpiredToken   = errors.New("token expired")
+)
+
+// Claims son los datos del token que usa la API
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+}
+
+// Config configura el Verifier; un token se acepta si lo firma cualquiera de
+// las fuentes de llaves configuradas
+type Config struct {
+	// Issuer esperado en "iss"; vacío no lo valida
+	Issuer string
+	// Audience que debe incluir "aud"; vacío no la valida
+	Audience string
+	// HMACSecret devuelve el secreto de los tokens HS*; se consulta en cada
+	// validación para respetar rotaciones
+	HMACSecret func() string
+	// JWKSURL publica las llaves RSA de los tokens RS*
+	JWKSURL string
+}
+
+type Verifier struct {
+	issuer     string
+	audience   string
+	hmacSecret func() string
+	jwks       *JWKS
+	clock      clock.Clock
+}
+
+func NewVerifier(config Config) *Verifier {
+	verifier := &Verifier{
+		issuer:     config.Issuer,
+		audience:   config.Audience,
+		hmacSecret: config.HMACSecret,
+		clock:      clock.Real{},
+	}
+	if config.JWKSURL != "" {
+		verifier.jwks = NewJWKS(config.JWKSURL)
+	}
+	return verifier
+}
+
+// SetClock reemplaza el reloj con el que se validan exp y nbf
+func (v *Verifier) SetClock(c clock.Clock) {
+	v.clock = c
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type tokenClaims struct {
+	Sub string   `json:"sub"`
+	Iss string   `json:"iss"`
+	Aud audience `json:"aud"`
+	Exp *float64 `json:"exp"`
+	Nbf *float64 `json:"nbf"`
+}
+
+// audience acepta "aud" como string o como lista, según el RFC 7519
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+var hashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+// Verify valida la firma y los claims del token y devuelve sus claims
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrMalformedToken
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, ErrMalformedToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, ErrMalformedToken
+	}
+	if err := v.verifySignature(ctx, header, parts[0]+"."+parts[1], signature); err != nil {
+		return Claims{}, err
+	}
+
+	var claims tokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, ErrMalformedToken
+	}
+	return v.validateClaims(claims)
+}
+
+func (v *Verifier) verifySignature(ctx context.Context, header tokenHeader, signed string, signature []byte) error {
+	if len(header.Alg) != 5 {
+		return fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+	hash, ok := hashes[header.Alg[2:]]
+	if !ok {
+		return fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+	switch header.Alg[:2] {
+	case "HS":
+		secret := ""
+		if v.hmacSecret != nil {
+			secret = v.hmacSecret()
+		}
+		if secret == "" {
+			return fmt.Errorf("unsupported algorithm %q", header.Alg)
+		}
+		mac := hmac.New(hash.New, []byte(secret))
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrInvalidToken
+		}
+		return nil
+	case "RS":
+		if v.jwks == nil {
+			return fmt.Errorf("unsupported algorithm %q", header.Alg)
+		}
+		key, err := v.jwks.Key(ctx, header.Kid)
+		if err != nil {
+			return err
+		}
+		digest := hash.New()
+		digest.Write([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(key, hash, digest.Sum(nil), signature); err != nil {
+			return ErrInvalidToken
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported algorithm %q", header.Alg)
+}
+
+func (v *Verifier) validateClaims(claims tokenClaims) (Claims, error) {
+	now := v.clock.Now()
+	if claims.Exp == nil {
+		return Claims{}, errors.New("token without expiration")
+	}
+	expiresAt := numericDate(*claims.Exp)
+	if now.After(expiresAt.Add(clockSkew)) {
+		return Claims{}, ErrExpiredToken
+	}
+	if claims.Nbf != nil && now.Add(clockSkew).Before(numericDate(*claims.Nbf)) {
+		return Claims{}, errors.New("token not valid yet")
+	}
+	if v.issuer != "" && claims.Iss != v.issuer {
+		return Claims{}, fmt.Errorf("unexpected issuer %q", claims.Iss)
+	}
+	if v.audience != "" && !slices.Contains(claims.Aud, v.audience) {
+		return Claims{}, errors.New("token not issued for this audience")
+	}
+	if claims.Sub == "" {
+		return Claims{}, errors.New("token without subject")
+	}
+
+	return Claims{
+		Subject:   claims.Sub,
+		Issuer:    claims.Iss,
+		Audience:  claims.Aud,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func numericDate(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"loan-agent/clock"
+)
+
+const (
+	testSecret   = "secreto-de-prueba"
+	testIssuer   = "https://idp.example.com"
+	testAudience = "loan-agent"
+	testKid      = "llave-1"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+var (
+	rsaKeyOnce sync.Once
+	rsaKey     *rsa.PrivateKey
+)
+
+// testRSAKey genera una sola vez la llave RSA de las pruebas
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	rsaKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+		rsaKey = key
+	})
+	return rsaKey
+}
+
+// jwksServer publica la llave pública de prueba como JWKS
+func jwksServer(t *testing.T, key *rsa.PublicKey) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{publicJWK(testKid, key)}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func publicJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// validClaims son claims aceptados por newTestVerifier
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub": "ana@example.com",
+		"iss": testIssuer,
+		"aud": testAudience,
+		"exp": testNow.Add(time.Hour).Unix(),
+		"nbf": testNow.Add(-time.Minute).Unix(),
+	}
+}
+
+func signHS256(t *testing.T, secret []byte, header, claims map[string]any) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims map[string]any) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]any{"alg": "RS256", "kid": testKid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// newTestVerifier crea un Verifier con el reloj fijo en testNow; secret o
+// jwksURL vacíos dejan esa fuente de llaves sin configurar
+func newTestVerifier(secret, jwksURL string) *Verifier {
+	verifier := NewVerifier(Config{
+		Issuer:     testIssuer,
+		Audience:   testAudience,
+		HMACSecret: func() string { return secret },
+		JWKSURL:    jwksURL,
+	})
+	verifier.SetClock(clock.NewManual(testNow))
+	return verifier
+}
+
+func TestVerifyAcceptsValidTokens(t *testing.T) {
+	key := testRSAKey(t)
+	server := jwksServer(t, &key.PublicKey)
+
+	tests := map[string]struct {
+		verifier *Verifier
+		token    string
+	}{
+		"HS256": {newTestVerifier(testSecret, ""), signHS256(t, []byte(testSecret), map[string]any{"alg": "HS256"}, validClaims())},
+		"RS256": {newTestVerifier("", server.URL), signRS256(t, key, validClaims())},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			claims, err := test.verifier.Verify(context.Background(), test.token)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.Subject != "ana@example.com" {
+				t.Errorf("Subject = %q, want %q", claims.Subject, "ana@example.com")
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsAlgNone(t *testing.T) {
+	key := testRSAKey(t)
+	server := jwksServer(t, &key.PublicKey)
+	verifier := newTestVerifier(testSecret, server.URL)
+
+	for _, alg := range []string{"none", "None", "NONE", ""} {
+		token := encodeSegment(t, map[string]any{"alg": alg}) + "." + encodeSegment(t, validClaims()) + "."
+		if _, err := verifier.Verify(context.Background(), token); err == nil {
+			t.Errorf("alg %q: token without signature was accepted", alg)
+		}
+	}
+}
+
+func TestVerifyRejectsHMACWhenOnlyJWKSIsConfigured(t *testing.T) {
+	key := testRSAKey(t)
+	server := jwksServer(t, &key.PublicKey)
+	verifier := newTestVerifier("", server.URL)
+
+	// Confusión de algoritmos: firmar con HMAC usando la llave pública como secreto
+	publicKey := key.PublicKey.N.Bytes()
+	for _, secret := range [][]byte{publicKey, []byte(""), []byte(testSecret)} {
+		token := signHS256(t, secret, map[string]any{"alg": "HS256", "kid": testKid}, validClaims())
+		if _, err := verifier.Verify(context.Background(), token); err == nil {
+			t.Errorf("HS256 token accepted by a JWKS-only verifier (secret %q)", secret)
+		}
+	}
+}
+
+func TestVerifyRejectsRSAWhenOnlyHMACIsConfigured(t *testing.T) {
+	verifier := newTestVerifier(testSecret, "")
+	token := signRS256(t, testRSAKey(t), validClaims())
+	if _, err := verifier.Verify(context.Background(), token); err == nil {
+		t.Error("RS256 token accepted by an HMAC-only verifier")
+	}
+}
+
+func TestVerifyRejectsTamperedSignature(t *testing.T) {
+	verifier := newTestVerifier(testSecret, "")
+	token := signHS256(t, []byte("otro-secreto"), map[string]any{"alg": "HS256"}, validClaims())
+	if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestVerifyClaims(t *testing.T) {
+	tests := map[string]struct {
+		change  func(claims map[string]any)
+		wantErr bool
+	}{
+		"expired": {
+			change:  func(c map[string]any) { c["exp"] = testNow.Add(-clockSkew - time.Second).Unix() },
+			wantErr: true,
+		},
+		"expired within clock skew": {
+			change: func(c map[string]any) { c["exp"] = testNow.Add(-clockSkew / 2).Unix() },
+		},
+		"without expiration": {
+			change:  func(c map[string]any) { delete(c, "exp") },
+			wantErr: true,
+		},
+		"not valid yet": {
+			change:  func(c map[string]any) { c["nbf"] = testNow.Add(clockSkew + time.Second).Unix() },
+			wantErr: true,
+		},
+		"not valid yet within clock skew": {
+			change: func(c map[string]any) { c["nbf"] = testNow.Add(clockSkew / 2).Unix() },
+		},
+		"issuer mismatch": {
+			change:  func(c map[string]any) { c["iss"] = "https://otro-idp.example.com" },
+			wantErr: true,
+		},
+		"without issuer": {
+			change:  func(c map[string]any) { delete(c, "iss") },
+			wantErr: true,
+		},
+		"audience mismatch": {
+			change:  func(c map[string]any) { c["aud"] = "otra-api" },
+			wantErr: true,
+		},
+		"audience list without ours": {
+			change:  func(c map[string]any) { c["aud"] = []string{"otra-api", "una-mas"} },
+			wantErr: true,
+		},
+		"audience list with ours": {
+			change: func(c map[string]any) { c["aud"] = []string{"otra-api", testAudience} },
+		},
+		"without subject": {
+			change:  func(c map[string]any) { delete(c, "sub") },
+			wantErr: true,
+		},
+	}
+
+	verifier := newTestVerifier(testSecret, "")
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			claims := validClaims()
+			test.change(claims)
+			token := signHS256(t, []byte(testSecret), map[string]any{"alg": "HS256"}, claims)
+
+			_, err := verifier.Verify(context.Background(), token)
+			if test.wantErr && err == nil {
+				t.Error("Verify() accepted the token")
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyExpiredTokenError(t *testing.T) {
+	verifier := newTestVerifier(testSecret, "")
+	claims := validClaims()
+	claims["exp"] = testNow.Add(-time.Hour).Unix()
+	token := signHS256(t, []byte(testSecret), map[string]any{"alg": "HS256"}, claims)
+
+	if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrExpiredToken)
+	}
+}
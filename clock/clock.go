@@ -0,0 +1,51 @@
+// Package clock abstrae la hora actual para que los limitadores, los
+// programadores y los planes fechados puedan simular el paso del tiempo.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock devuelve la hora actual
+type Clock interface {
+	Now() time.Time
+}
+
+// Real usa la hora del sistema
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Manual es un reloj que solo avanza cuando se le indica; sirve para
+// simulaciones deterministas y para generar planes "a la fecha" de otro día
+type Manual struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewManual(now time.Time) *Manual {
+	return &Manual{now: now}
+}
+
+func (c *Manual) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set fija la hora actual
+func (c *Manual) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance adelanta el reloj la duración indicada
+func (c *Manual) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// AnalyticsBucket agrega la actividad de un intervalo de tiempo
+type AnalyticsBucket struct {
+	Start               time.Time
+	Requests            map[string]int // por endpoint
+	TotalRequests       int
+	Errors              int
+	RateLimitRejections int
+	LoanCount           int
+	LoanAmountTotal     float64
+	AverageLoanAmount   float64
+	StrategyMix         map[string]int
+}
+
+type AnalyticsOverview struct {
+	From     time.Time
+	To       time.Time
+	Interval string // "hour", "day"
+	Totals   AnalyticsBucket
+	Series   []AnalyticsBucket
+}
@@ -0,0 +1,30 @@
+package domain
+
+type BalanceTransferOffer struct {
+	DebtNames    []string // deudas cuyo saldo se traslada
+	FeePercent   float64  // comisión de traslado sobre el saldo trasladado
+	PromoRate    float64  // tasa anual durante la promoción
+	PromoMonths  int
+	StandardRate float64 // tasa anual al terminar la promoción
+	// Pago mínimo de la nueva deuda; por defecto la suma de los mínimos trasladados
+	MinimumPayment float64 `json:",omitempty"`
+}
+
+type BalanceTransferInput struct {
+	Debts                   []Debt
+	AvailableMonthlyPayment float64
+	Strategy                string // "snowball", "avalanche"
+	Offer                   BalanceTransferOffer
+	Language                string `json:",omitempty"` // idioma de la explicación: "es" (por defecto) o "en"
+}
+
+type BalanceTransferResult struct {
+	TransferredAmount float64
+	TransferFee       float64
+	WithoutTransfer   StrategyResult
+	WithTransfer      StrategyResult // TotalInterestPaid no incluye la comisión
+	NetSavings        float64        // intereses evitados menos la comisión; negativo si el traslado cuesta más
+	SavesMoney        bool
+	BreakEvenMonth    int `json:",omitempty"` // mes desde el cual el traslado ya resulta más barato
+	Explanation       string
+}
@@ -0,0 +1,10 @@
+package domain
+
+// ConfigEntry es un valor de configuración resuelto y su origen:
+// "default", "env", "secrets:<proveedor>" o "admin-override"
+type ConfigEntry struct {
+	Name   string
+	Value  string
+	Source string
+	Secret bool `json:",omitempty"` // el valor se oculta en la respuesta
+}
@@ -0,0 +1,36 @@
+package domain
+
+type ConsolidationOffer struct {
+	InterestRate          float64
+	TermMonths            int
+	OriginationFeePercent float64 // comisión sobre el monto del préstamo
+	FlatFees              float64 // gastos de cierre fijos
+	FinanceFees           bool    // si las comisiones se suman al préstamo
+}
+
+type ConsolidationInput struct {
+	Debts                   []Debt
+	AvailableMonthlyPayment float64
+	Offer                   ConsolidationOffer
+	Language                string `json:",omitempty"` // idioma de la explicación: "es" (por defecto) o "en"
+}
+
+type ConsolidationLoanResult struct {
+	LoanAmount     float64
+	MonthlyPayment float64
+	TotalInterest  float64
+	TotalFees      float64
+	TotalCost      float64 // intereses más comisiones
+	MonthsToPayoff int
+	Affordable     bool // la cuota cabe en el pago mensual disponible
+}
+
+type ConsolidationResult struct {
+	TotalDebt      float64
+	Consolidation  ConsolidationLoanResult
+	Snowball       StrategyResult
+	Avalanche      StrategyResult
+	Recommendation string  // "consolidate", "snowball", "avalanche"
+	CostSavings    float64 // ahorro de la opción recomendada frente a la mejor alternativa
+	Explanation    string
+}
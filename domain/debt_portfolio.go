@@ -1,50 +1,250 @@
 package domain
 
+// RecurringFee es un cargo periódico de una deuda (anualidad de tarjeta,
+// mantenimiento mensual) que se suma al saldo mientras la deuda esté activa
+type RecurringFee struct {
+	Description string `json:",omitempty"`
+	Amount      float64
+	EveryMonths int // 1 mensual, 12 anual
+	FirstMonth  int `json:",omitempty"` // primer mes del plan en que se cobra; por defecto 1
+}
+
 type Debt struct {
 	Name           string
 	Amount         float64
-	InterestRate   float64
+	InterestRate   float64 // tasa estándar (después de la promoción, si hay)
 	MinimumPayment float64
+	// Tasa promocional durante los primeros PromoMonths meses (ej. 0% por traslado de saldo)
+	PromoInterestRate float64 `json:",omitempty"`
+	PromoMonths       int     `json:",omitempty"`
+	// Mes en que se adquiere la deuda; 0 o 1 significa que ya existe hoy
+	StartMonth int `json:",omitempty"`
+	// Moneda de Amount y MinimumPayment: "USD" (por defecto) o "NIO"
+	Currency string `json:",omitempty"`
+	// Capitalización del interés: "simple_monthly" (por defecto, sobre el saldo
+	// de apertura), "daily" o "average_daily_balance"
+	Compounding string `json:",omitempty"`
+	// Cargos periódicos en la misma moneda de la deuda
+	Fees []RecurringFee `json:",omitempty"`
+}
+
+// LumpSum es un pago extra único en un mes del plan (aguinaldo, devolución de impuestos)
+type LumpSum struct {
+	Month       int
+	Amount      float64
+	Description string `json:",omitempty"`
+}
+
+// HardshipMonth marca un mes de ingreso irregular en el que solo se pagan los
+// mínimos ("minimums") o no se paga nada ("skip")
+type HardshipMonth struct {
+	Month int
+	Mode  string
+}
+
+// Snowflake es un pago extra pequeño y fechado (venta de algo usado, un
+// trabajo ocasional) que se suma al presupuesto del mes
+type Snowflake struct {
+	Month       int
+	Amount      float64
+	DebtName    string `json:",omitempty"` // vacío: la deuda foco de la estrategia
+	Description string `json:",omitempty"`
+}
+
+type PaymentStep struct {
+	Month  int
+	Amount float64 // nuevo pago mensual disponible a partir de ese mes
+}
+
+// PaymentGrowth describe cómo crece el pago mensual disponible con el tiempo.
+// Cada escalón fija el pago desde su mes y AnnualPercent se compone cada 12
+// meses contados desde el último escalón alcanzado (o desde el mes 1)
+type PaymentGrowth struct {
+	AnnualPercent float64       `json:",omitempty"` // aumento compuesto cada 12 meses
+	Steps         []PaymentStep `json:",omitempty"`
+}
+
+// RoundUpProfile describe un programa de redondeo: cada compra se redondea a
+// la unidad indicada y la diferencia se abona a las deudas
+type RoundUpProfile struct {
+	TransactionsPerMonth int
+	RoundingUnit         float64 // ej. 1.0 redondea al dólar siguiente
+	Multiplier           float64 `json:",omitempty"` // ej. 2 duplica cada redondeo; por defecto 1
+}
+
+// StrategyWeights pondera la tasa y el saldo en la estrategia "weighted": solo
+// tasa equivale a Avalanche y solo saldo a Snowball
+type StrategyWeights struct {
+	InterestRate float64
+	Balance      float64
 }
 
 type DebtExitInput struct {
 	Debts                   []Debt
 	AvailableMonthlyPayment float64
-	Strategy                string // "snowball", "avalanche", "compare"
+	Strategy                string           // "snowball", "avalanche", "cfi", "weighted", "compare"
+	StrategyWeights         *StrategyWeights `json:",omitempty"` // requerido con "weighted"
+	// Con "avalanche", liquidar al menos una deuda en los primeros QuickWinMonths meses
+	QuickWinMonths       int             `json:",omitempty"`
+	LumpSums             []LumpSum       `json:",omitempty"`
+	Snowflakes           []Snowflake     `json:",omitempty"`
+	PaymentGrowth        *PaymentGrowth  `json:",omitempty"`
+	RoundUp              *RoundUpProfile `json:",omitempty"`
+	IncludeExplanation   *bool           `json:",omitempty"` // false devuelve solo resultados numéricos; por defecto true
+	BilingualExplanation bool            `json:",omitempty"` // incluir la explicación en español e inglés
+	MonthlyIncome        float64         `json:",omitempty"` // ingreso mensual en USD para la relación deuda/ingreso
+	ReadingLevel         string          `json:",omitempty"` // "basic", "standard" (por defecto), "advanced"
+	Language             string          `json:",omitempty"` // idioma de la explicación: "es" (por defecto) o "en"
+	StartDate            string          `json:",omitempty"` // mes 1 del plan (AAAA-MM); por defecto el mes actual
+	HardshipMonths       []HardshipMonth `json:",omitempty"`
+	// Cómo se devuelve MonthlyPlan: "full" (por defecto), "annual" (solo
+	// resúmenes por año) o "page" (una ventana de PageSize meses)
+	PlanView string `json:",omitempty"`
+	Page     int    `json:",omitempty"` // página a devolver con "page", desde 1
+	PageSize int    `json:",omitempty"` // meses por página; por defecto 12
+	// En el modo "compare", devolver el plan de cada estrategia en Comparison
+	IncludeComparisonPlans bool `json:",omitempty"`
 }
 
 type MonthlyPayment struct {
 	DebtName         string
 	Payment          float64
+	Interest         float64 // parte del pago que cubre intereses
+	Principal        float64 // parte del pago que abona a capital (incluye cargos periódicos)
 	RemainingBalance float64
 }
 
 type MonthlyPlan struct {
-	Month     int
-	Payments  []MonthlyPayment
-	TotalPaid float64
+	Month          int
+	Date           string // AAAA-MM
+	Hardship       string `json:",omitempty"` // "minimums" o "skip" si el mes fue de dificultad
+	Payments       []MonthlyPayment
+	TotalPaid      float64
+	TotalInterest  float64
+	TotalPrincipal float64
+}
+
+// DebtSummary resume cuándo se liquida cada deuda y cuánto costó
+type DebtSummary struct {
+	DebtName          string
+	PayoffMonth       int // 0 si no se liquida dentro del límite de meses
+	TotalInterestPaid float64
+	TotalFeesPaid     float64 `json:",omitempty"`
+	TotalPaid         float64
 }
 
 type StrategyResult struct {
 	TotalInterestPaid float64
+	TotalFeesPaid     float64 `json:",omitempty"`
 	MonthsToPayoff    int
+	// Plan completo de la estrategia en el modo "compare" con IncludeComparisonPlans
+	MonthlyPlan     []MonthlyPlan    `json:",omitempty"`
+	AnnualSummaries []AnnualSummary  `json:",omitempty"`
+	Page            *MonthlyPlanPage `json:",omitempty"`
 }
 
 type Comparison struct {
 	Snowball  StrategyResult
 	Avalanche StrategyResult
+	CFI       StrategyResult // índice de flujo de caja: saldo / pago mínimo
 	Savings   struct {
 		InterestSaved float64
 		MonthsSaved   int
 	}
 }
 
+// MinimumsBaseline compara el plan contra pagar solo los mínimos, sin redirigir
+// el excedente ni el pago de las deudas liquidadas
+type MinimumsBaseline struct {
+	TotalInterestPaid float64
+	MonthsToPayoff    int
+	InterestSaved     float64 // intereses que el plan ahorra frente a pagar solo mínimos
+	MonthsSaved       int
+}
+
+// DebtHealthMetrics resume la situación de la cartera de deudas al inicio del plan
+type DebtHealthMetrics struct {
+	WeightedAverageAPR      float64 // tasa anual promedio ponderada por saldo
+	DebtToIncome            float64 `json:",omitempty"` // pagos mínimos / ingreso (%), si se indicó el ingreso
+	FirstMonthInterestShare float64 // porcentaje del presupuesto del mes 1 que se va en intereses
+	RiskLevel               string  // "low", "medium", "high"
+}
+
+// AnnualSummary agrupa los meses del plan por año del plan (meses 1-12, 13-24, ...)
+type AnnualSummary struct {
+	Year           int
+	FromDate       string // AAAA-MM
+	ToDate         string // AAAA-MM
+	TotalPaid      float64
+	TotalInterest  float64
+	TotalPrincipal float64
+	DebtsPaidOff   []string `json:",omitempty"` // deudas liquidadas durante el año
+}
+
+// MonthlyPlanPage describe la ventana de meses devuelta en MonthlyPlan
+type MonthlyPlanPage struct {
+	Page        int
+	PageSize    int
+	TotalMonths int
+	TotalPages  int
+}
+
+// QuickWinResult describe la desviación mínima de Avalanche necesaria para
+// liquidar una deuda dentro de los primeros meses pedidos
+type QuickWinResult struct {
+	Months           int
+	Satisfied        bool   // algún orden de pago cumple la restricción
+	PrioritizedDebt  string `json:",omitempty"` // deuda adelantada; vacío si Avalanche puro ya cumple
+	FirstPayoffMonth int
+	ExtraInterest    float64 // intereses adicionales frente a Avalanche puro
+}
+
+// HardshipImpact es lo que cuestan los meses de dificultad frente al mismo plan sin ellos
+type HardshipImpact struct {
+	ExtraMonths   int
+	ExtraInterest float64
+}
+
 type DebtExitResult struct {
 	Strategy          string
+	Currency          string // moneda de todos los montos del resultado
 	TotalDebt         float64
+	RoundUpExtra      float64 `json:",omitempty"` // aporte mensual estimado de los redondeos
 	TotalInterestPaid float64
+	TotalFeesPaid     float64 `json:",omitempty"` // cargos periódicos cobrados durante el plan
 	MonthsToPayoff    int
-	MonthlyPlan       []MonthlyPlan
-	Comparison        *Comparison `json:",omitempty"`
-	Explanation       string      `json:",omitempty"` // Explicación generada por IA
+	StartDate         string // AAAA-MM del mes 1
+	PayoffDate        string // AAAA-MM del último pago
+	DebtSummaries     []DebtSummary
+	MonthlyPlan       []MonthlyPlan     `json:",omitempty"` // vacío con PlanView "annual"
+	AnnualSummaries   []AnnualSummary   `json:",omitempty"`
+	Page              *MonthlyPlanPage  `json:",omitempty"`
+	Comparison        *Comparison       `json:",omitempty"`
+	Baseline          *MinimumsBaseline `json:",omitempty"`
+	Health            DebtHealthMetrics
+	Hardship          *HardshipImpact   `json:",omitempty"`
+	QuickWin          *QuickWinResult   `json:",omitempty"`
+	Explanation       string            `json:",omitempty"` // Explicación generada por IA
+	Explanations      map[string]string `json:",omitempty"` // explicación por idioma si se pidió bilingüe
+}
+
+// TargetPayoffInput pide el pago mensual necesario para salir de deudas en TargetMonths
+type TargetPayoffInput struct {
+	DebtExitInput     // AvailableMonthlyPayment, si se indica, es el presupuesto actual
+	TargetMonths  int // plazo deseado para quedar libre de deudas
+}
+
+type TargetPayoffStrategyResult struct {
+	Strategy               string
+	Reachable              bool // existe algún pago que cumpla el plazo
+	Feasible               bool // alcanzable y dentro del presupuesto actual (si se indicó)
+	RequiredMonthlyPayment float64
+	AdditionalPayment      float64 `json:",omitempty"` // diferencia contra el presupuesto actual
+	MonthsToPayoff         int
+	TotalInterestPaid      float64
+}
+
+type TargetPayoffResult struct {
+	TargetMonths int
+	Results      []TargetPayoffStrategyResult
 }
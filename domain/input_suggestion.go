@@ -0,0 +1,14 @@
+package domain
+
+// InputSuggestion señala un valor que probablemente se ingresó mal (plazo en
+// años, tasa mensual, monto sin los miles) y el valor que quizá se quiso enviar.
+// Code y Args arman Message en el idioma de la respuesta
+type InputSuggestion struct {
+	Field          string
+	Value          float64
+	SuggestedValue float64
+	Message        string
+
+	Code string `json:"-"`
+	Args []any  `json:"-"`
+}
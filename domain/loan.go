@@ -1,13 +1,89 @@
 package domain
 
+import "time"
+
+type Insurance struct {
+	Name  string
+	Type  string // "percentage" (mensual sobre saldo), "flat" (monto fijo mensual)
+	Value float64
+}
+
+type Collateral struct {
+	Type           string // "real_estate", "vehicle", "deposit", "equipment"
+	AppraisedValue float64
+}
+
 type LoanInput struct {
 	Amount       float64
 	InterestRate float64
 	TermMonths   int
+	Insurances   []Insurance `json:",omitempty"`
+	Tags         []string    `json:",omitempty"` // campaña, sucursal, asesor, etc.
+	Collateral   *Collateral `json:",omitempty"`
+	// Comparar la tasa con la mediana de los cálculos guardados
+	IncludeBenchmark bool `json:",omitempty"`
+	// Incluir la tabla de amortización mes a mes en el resultado
+	IncludeSchedule bool `json:",omitempty"`
+	// Idioma de los mensajes: "es" (por defecto) o "en"
+	Language string `json:",omitempty"`
+	// Usuario autenticado que hizo el cálculo; lo asigna el handler a partir del JWT
+	UserID string `json:"-"`
+	// Request sandbox: el cálculo no se guarda; lo asigna el handler
+	Sandbox bool `json:"-"`
+}
+
+type AmortizationEntry struct {
+	Month            int
+	Payment          float64
+	Principal        float64
+	Interest         float64
+	Insurance        float64
+	RemainingBalance float64
+}
+
+type CollateralAssessment struct {
+	Type                 string
+	AppraisedValue       float64
+	LoanToValue          float64 // porcentaje
+	MaxLoanToValue       float64 // porcentaje
+	MaxFinanceableAmount float64
+}
+
+// RateBenchmark ubica una tasa frente a las tasas de los cálculos guardados;
+// solo se devuelve con suficientes muestras
+type RateBenchmark struct {
+	Rate       float64
+	MedianRate float64
+	Percentile float64 // porcentaje de cálculos con una tasa menor
+	SampleSize int
+	Position   string // "above", "below", "at_median"
+	Message    string
 }
 
 type LoanResult struct {
-	MonthlyPayment float64
-	TotalPayment   float64
-	TotalInterest  float64
+	MonthlyPayment float64 // cuota de capital e interés, sin seguros
+	// Prima de seguros del primer mes, la más alta cuando el seguro se calcula
+	// sobre el saldo; la cuota con seguros es MonthlyPayment + MonthlyInsurance
+	MonthlyInsurance float64 `json:",omitempty"`
+	TotalPayment     float64 // total de capital e interés, sin seguros
+	TotalInterest    float64
+	TotalInsurance   float64
+	Schedule         []AmortizationEntry   `json:",omitempty"`
+	Collateral       *CollateralAssessment `json:",omitempty"`
+	Warnings         []string              `json:",omitempty"`
+	Suggestions      []InputSuggestion     `json:",omitempty"` // posibles errores de captura
+	Benchmark        *RateBenchmark        `json:",omitempty"`
+}
+
+// LoanRecord es un cálculo de préstamo guardado en el repositorio
+type LoanRecord struct {
+	UserID    string `json:",omitempty"`
+	Input     LoanInput
+	Result    LoanResult
+	CreatedAt time.Time
+}
+
+type TagCount struct {
+	Tag   string
+	Count int
 }
@@ -0,0 +1,24 @@
+package domain
+
+type PaymentAllocationInput struct {
+	DebtName             string `json:",omitempty"`
+	Balance              float64
+	InterestRate         float64 // tasa anual
+	Payment              float64
+	FeesDue              float64 `json:",omitempty"` // comisiones o cargos pendientes
+	AccrualConvention    string  `json:",omitempty"` // "monthly" (por defecto), "daily"
+	DaysSinceLastPayment int     `json:",omitempty"` // para "daily"; por defecto 30
+}
+
+type PaymentAllocationResult struct {
+	DebtName          string `json:",omitempty"`
+	AccruedInterest   float64
+	ToFees            float64
+	ToInterest        float64
+	ToPrincipal       float64
+	Unapplied         float64 // excedente sobre el saldo total adeudado
+	RemainingBalance  float64
+	RemainingFees     float64
+	RemainingInterest float64
+	Explanation       string
+}
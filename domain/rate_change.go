@@ -0,0 +1,22 @@
+package domain
+
+// RateChangeInput describe la posición actual de un préstamo y la nueva tasa tras una revisión
+type RateChangeInput struct {
+	RemainingBalance    float64
+	RemainingTermMonths int
+	CurrentRate         float64     // tasa anual vigente
+	NewRate             float64     // tasa anual tras la revisión
+	Insurances          []Insurance `json:",omitempty"`
+	Language            string      `json:",omitempty"` // idioma de la explicación: "es" (por defecto) o "en"
+}
+
+type RateChangeResult struct {
+	OldMonthlyPayment float64
+	NewMonthlyPayment float64
+	PaymentDelta      float64 // positivo si la cuota sube
+	OldTotalInterest  float64
+	NewTotalInterest  float64
+	InterestDelta     float64 // positivo si se pagan más intereses
+	Schedule          []AmortizationEntry
+	Explanation       string
+}
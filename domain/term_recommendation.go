@@ -1,23 +1,130 @@
 package domain
 
+type IncomeSource struct {
+	Type          string // "salary", "business", "remittance", etc.
+	MonthlyAmount float64
+}
+
+type Obligation struct {
+	Name           string
+	MonthlyPayment float64
+}
+
+type Borrower struct {
+	Name        string
+	Role        string // "primary", "co_borrower", "co_signer"
+	Incomes     []IncomeSource
+	Obligations []Obligation `json:",omitempty"`
+}
+
+// Affordability resume la capacidad de pago combinada de los deudores
+type Affordability struct {
+	TotalIncome         float64
+	QualifyingIncome    float64 // ingreso ponderado según el rol de cada deudor
+	ExistingObligations float64
+	MaxDebtToIncome     float64 // porcentaje
+	MonthlyCapacity     float64
+}
+
+// ScoringWeights reemplaza los pesos de la preferencia al puntuar cada plazo;
+// deben sumar 1
+type ScoringWeights struct {
+	Interest float64
+	Payment  float64
+	Term     float64
+	Cost     float64 `json:",omitempty"` // costo total con seguros y cargos
+}
+
+// ScoreBreakdown detalla cómo se obtuvo el Score de un plazo: cada componente
+// va de 0 a 10 y el Score es la suma ponderada con Weights
+type ScoreBreakdown struct {
+	InterestScore float64
+	PaymentScore  float64
+	TermScore     float64
+	CostScore     float64
+	Weights       ScoringWeights // los de ScoringWeights o los de la preferencia
+}
+
+// RateTier es la tasa ofrecida para los plazos de hasta MaxTermMonths meses
+// que no cubre un tramo más corto (ej. hasta 12 meses al 14%, hasta 36 al 17%)
+type RateTier struct {
+	MaxTermMonths int
+	InterestRate  float64
+}
+
 type TermRecommendationInput struct {
-	Amount            float64
-	InterestRate      float64
+	Amount       float64
+	InterestRate float64
+	// Tasas por tramo de plazo en lugar de InterestRate; los plazos más largos
+	// que el último tramo no se ofrecen
+	RateTiers         []RateTier `json:",omitempty"`
 	MinTermMonths     int
 	MaxTermMonths     int
+	TermStepMonths    int `json:",omitempty"` // evaluar cada N meses desde MinTermMonths (ej. 12); por defecto 1
 	MaxMonthlyPayment float64
-	Preference        string // "minimize_interest", "minimize_payment", "balanced"
+	// Seguros del préstamo; entran en la cuota, el costo total y el score de cada plazo
+	Insurances []Insurance `json:",omitempty"`
+	// "minimize_interest", "minimize_payment", "balanced", "fastest_payoff",
+	// "minimize_total_cost_with_fees"
+	Preference     string
+	ScoringWeights *ScoringWeights `json:",omitempty"` // pesos propios en lugar de los de Preference
+	Borrowers      []Borrower      `json:",omitempty"`
+	// Ingreso mensual y relación cuota/ingreso máxima (%); por defecto MAX_DTI
+	MonthlyIncome      float64 `json:",omitempty"`
+	MaxPaymentToIncome float64 `json:",omitempty"`
+	// Explicación de cada plazo: false devuelve solo resultados numéricos (por
+	// defecto true); Bilingual la incluye en español e inglés
+	IncludeExplanation   *bool  `json:",omitempty"`
+	BilingualExplanation bool   `json:",omitempty"`
+	ReadingLevel         string `json:",omitempty"` // "basic", "standard" (por defecto), "advanced"
+	Language             string `json:",omitempty"` // idioma de la explicación: "es" (por defecto) o "en"
+	// Comparar la tasa con la mediana de los cálculos guardados
+	IncludeBenchmark bool `json:",omitempty"`
+	// Devolver en Rejected los plazos descartados y el motivo
+	IncludeRejected bool `json:",omitempty"`
+}
+
+// RejectedTerm es un plazo evaluado que no se recomienda
+type RejectedTerm struct {
+	TermMonths     int
+	MonthlyPayment float64 `json:",omitempty"` // cuota que habría requerido el plazo
+	Reason         string
 }
 
 type TermRecommendation struct {
 	TermMonths     int
+	InterestRate   float64 // tasa aplicada al plazo
 	MonthlyPayment float64
 	TotalInterest  float64
+	TotalInsurance float64 `json:",omitempty"`
+	TotalCost      float64 // total a pagar, con seguros y cargos
 	Score          float64
-	Reason         string
+	ScoreBreakdown ScoreBreakdown
+	DebtToIncome   float64 `json:",omitempty"` // (cuota + obligaciones) / ingreso (%), si se conoce el ingreso
+	// Ningún otro plazo tiene a la vez menor cuota y menor costo total
+	ParetoOptimal bool
+	Reason        string
+	Reasons       map[string]string `json:",omitempty"` // explicación por idioma si se pidió bilingüe
+}
+
+// RateSensitivity muestra cuánto cambian la cuota y el costo total del plazo
+// recomendado si la tasa se mueve RateChange puntos
+type RateSensitivity struct {
+	RateChange     float64 // puntos porcentuales, ej. -1 o +2
+	InterestRate   float64
+	MonthlyPayment float64
+	TotalCost      float64
+	PaymentChange  float64 // diferencia contra la cuota recomendada
+	CostChange     float64 // diferencia contra el costo total recomendado
 }
 
 type TermRecommendationResult struct {
 	RecommendedTerm int
 	Recommendations []TermRecommendation
+	// Cuota y costo del plazo recomendado con la tasa ±1 y ±2 puntos
+	Sensitivity   []RateSensitivity `json:",omitempty"`
+	Rejected      []RejectedTerm    `json:",omitempty"` // solo con IncludeRejected
+	Affordability *Affordability    `json:",omitempty"`
+	Suggestions   []InputSuggestion `json:",omitempty"` // posibles errores de captura
+	Benchmark     *RateBenchmark    `json:",omitempty"`
 }
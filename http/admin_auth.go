@@ -0,0 +1,32 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// AdminAuthMiddleware exige "Authorization: Bearer <token>" con el token de
+// administración. El token se consulta en cada request para respetar rotaciones;
+// sin token configurado los endpoints de admin quedan deshabilitados.
+func AdminAuthMiddleware(
+	adminToken func() string,
+	next http.Handler,
+) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := adminToken()
+		if token == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			writeError(w, r, http.StatusUnauthorized, codeUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,196 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"loan-agent/domain"
+	"loan-agent/service"
+)
+
+// adminSecretNames son los secretos leídos del proveedor de secretos que se
+// reportan (ocultos) en la configuración efectiva
+var adminSecretNames = []string{"ADMIN_TOKEN", "DATA_ENCRYPTION_KEYS", "JWT_HMAC_SECRET", "RATE_LIMIT_TIERS"}
+
+type AdminHandler struct {
+	maintenance     *MaintenanceMode
+	readiness       *Readiness
+	rateLimiter     *RateLimiter
+	secretLookup    func(name string) string
+	secretsProvider string
+}
+
+// NewAdminHandler crea el handler de administración; secretLookup resuelve un
+// secreto con el proveedor configurado (secretsProvider: "env", "file", "vault")
+func NewAdminHandler(
+	maintenance *MaintenanceMode,
+	readiness *Readiness,
+	rateLimiter *RateLimiter,
+	secretLookup func(name string) string,
+	secretsProvider string,
+) *AdminHandler {
+	return &AdminHandler{
+		maintenance:     maintenance,
+		readiness:       readiness,
+		rateLimiter:     rateLimiter,
+		secretLookup:    secretLookup,
+		secretsProvider: secretsProvider,
+	}
+}
+
+// EffectiveConfig devuelve la configuración resuelta con su origen; los
+// secretos se ocultan y el mantenimiento activo aparece como override de admin
+func (h *AdminHandler) EffectiveConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	entries := service.EffectiveConfig()
+	for _, name := range adminSecretNames {
+		entry := domain.ConfigEntry{Name: name, Source: "default", Secret: true}
+		if h.secretLookup(name) != "" {
+			entry.Value = service.RedactedValue
+			entry.Source = "secrets:" + h.secretsProvider
+		}
+		if _, overridden := h.rateLimiter.Tiers(); overridden && name == "RATE_LIMIT_TIERS" {
+			entry.Value = service.RedactedValue
+			entry.Source = "admin-override"
+		}
+		entries = append(entries, entry)
+	}
+
+	if window := h.maintenance.Window(); window != nil {
+		value := "scheduled " + window.StartsAt.UTC().Format(time.RFC3339)
+		if !h.maintenance.Now().Before(window.StartsAt) {
+			value = "active since " + window.StartsAt.UTC().Format(time.RFC3339)
+		}
+		entries = append(entries, domain.ConfigEntry{
+			Name:   "MAINTENANCE_WINDOW",
+			Value:  value,
+			Source: "admin-override",
+		})
+	}
+
+	writeJSON(w, r, entries)
+}
+
+// Maintenance consulta (GET), programa o activa (PUT) y desactiva (DELETE) el modo mantenimiento
+func (h *AdminHandler) Maintenance(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, r, h.maintenance.Window())
+
+	case http.MethodPut:
+		contentType := r.Header.Get("Content-Type")
+		if !strings.Contains(contentType, "application/json") {
+			writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
+			return
+		}
+
+		var window MaintenanceWindow
+		if err := json.NewDecoder(r.Body).Decode(&window); err != nil {
+			slog.ErrorContext(r.Context(), "decoding request body", "error", err)
+			writeBodyError(w, r, err)
+			return
+		}
+		if window.StartsAt.IsZero() {
+			window.StartsAt = h.maintenance.Now()
+		}
+		if window.EndsAt != nil && !window.EndsAt.After(window.StartsAt) {
+			writeError(w, r, http.StatusBadRequest, codeInvalidMaintenanceWindow)
+			return
+		}
+
+		h.maintenance.Set(window)
+		slog.InfoContext(r.Context(), "maintenance window set", "starts_at", window.StartsAt.Format(time.RFC3339))
+		writeJSON(w, r, window)
+
+	case http.MethodDelete:
+		h.maintenance.Clear()
+		slog.InfoContext(r.Context(), "maintenance window cleared")
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+	}
+}
+
+type rateLimitsResponse struct {
+	// Groups es el límite por IP de cada grupo de rutas, configurado por entorno
+	Groups     map[string]RateLimitTier
+	Overridden bool
+	Tiers      []RateLimitTierSummary
+}
+
+func (h *AdminHandler) writeRateLimits(w http.ResponseWriter, r *http.Request) {
+	tiers, overridden := h.rateLimiter.Tiers()
+	writeJSON(w, r, rateLimitsResponse{
+		Groups:     h.rateLimiter.GroupLimits(),
+		Overridden: overridden,
+		Tiers:      tiers,
+	})
+}
+
+// RateLimits consulta (GET), reemplaza (PUT) y restablece a la configuración
+// de arranque (DELETE) los tiers de rate limit por API key
+func (h *AdminHandler) RateLimits(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.writeRateLimits(w, r)
+
+	case http.MethodPut:
+		contentType := r.Header.Get("Content-Type")
+		if !strings.Contains(contentType, "application/json") {
+			writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
+			return
+		}
+
+		var config RateLimitConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			slog.ErrorContext(r.Context(), "decoding request body", "error", err)
+			writeBodyError(w, r, err)
+			return
+		}
+		if err := config.Validate(); err != nil {
+			writeError(w, r, http.StatusBadRequest, codeInvalidRateLimitConfig, err.Error())
+			return
+		}
+
+		h.rateLimiter.OverrideTiers(config)
+		slog.InfoContext(r.Context(), "rate limit tiers overridden", "tiers", len(config.Tiers), "api_keys", len(config.APIKeys))
+		h.writeRateLimits(w, r)
+
+	case http.MethodDelete:
+		h.rateLimiter.ResetTiers()
+		slog.InfoContext(r.Context(), "rate limit tiers reset to startup configuration")
+		h.writeRateLimits(w, r)
+
+	default:
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+	}
+}
+
+type drainResponse struct {
+	Draining         bool
+	RemainingSeconds float64
+}
+
+// Drain es el hook pre-stop: /readyz empieza a fallar mientras se sigue
+// atendiendo el tráfico en curso
+func (h *AdminHandler) Drain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	h.readiness.StartDrain()
+	slog.InfoContext(r.Context(), "drain started, /readyz now reports not ready")
+	writeJSON(w, r, drainResponse{
+		Draining:         true,
+		RemainingSeconds: h.readiness.DrainRemaining().Seconds(),
+	})
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"loan-agent/service"
+)
+
+type AnalyticsHandler struct {
+	service *service.AnalyticsService
+}
+
+func NewAnalyticsHandler(service *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{service: service}
+}
+
+// parseTimeRange lee from/to (RFC 3339) de la query; por defecto las últimas 24 horas
+func parseTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+	to := time.Now().UTC()
+	from := to.Add(-24 * time.Hour)
+
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = parsed
+		from = to.Add(-24 * time.Hour)
+	}
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = parsed
+	}
+	return from, to, nil
+}
+
+func (h *AnalyticsHandler) Overview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, codeInvalidTimeRange)
+		return
+	}
+
+	interval := r.URL.Query().Get("interval")
+	if interval == "" {
+		interval = "hour"
+	}
+
+	overview, err := h.service.Overview(from, to, interval)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "building analytics overview", "error", err)
+		writeServiceError(w, r, err, preferredLanguage(r))
+		return
+	}
+
+	writeJSON(w, r, overview)
+}
+
+func (h *AnalyticsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, codeInvalidTimeRange)
+		return
+	}
+
+	interval := r.URL.Query().Get("interval")
+	if interval == "" {
+		interval = "hour"
+	}
+
+	overview, err := h.service.Overview(from, to, interval)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "building analytics overview", "error", err)
+		writeServiceError(w, r, err, preferredLanguage(r))
+		return
+	}
+
+	// Escribir CSV en buffer primero para evitar escribir header si falla
+	var buf bytes.Buffer
+	if err := h.service.ExportCSV(overview, &buf); err != nil {
+		slog.ErrorContext(r.Context(), "encoding CSV export", "error", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="analytics.csv"`)
+	if _, err := buf.WriteTo(w); err != nil {
+		slog.ErrorContext(r.Context(), "writing response", "error", err)
+	}
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+
+	"loan-agent/service"
+)
+
+// statusRecorder captura el status code escrito por el handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func AnalyticsMiddleware(
+	analytics *service.AnalyticsService,
+	next http.Handler,
+) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		analytics.RecordRequest(r.URL.Path, recorder.status)
+	})
+}
@@ -0,0 +1,12 @@
+package http
+
+import "net/http"
+
+// APIVersionMiddleware indica en el header API-Version qué versión de la API
+// atendió la request, también cuando llega por una ruta sin prefijo
+func APIVersionMiddleware(version string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", version)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"loan-agent/domain"
+	"loan-agent/service"
+)
+
+type BalanceTransferHandler struct {
+	service *service.BalanceTransferService
+}
+
+func NewBalanceTransferHandler(service *service.BalanceTransferService) *BalanceTransferHandler {
+	return &BalanceTransferHandler{service: service}
+}
+
+func (h *BalanceTransferHandler) AnalyzeBalanceTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
+		return
+	}
+
+	var input domain.BalanceTransferInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		slog.ErrorContext(r.Context(), "decoding request body", "error", err)
+		writeBodyError(w, r, err)
+		return
+	}
+	if input.Language == "" {
+		input.Language = preferredLanguage(r)
+	}
+
+	result, err := h.service.AnalyzeBalanceTransfer(input)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "analyzing balance transfer", "error", err)
+		writeServiceError(w, r, err, input.Language)
+		return
+	}
+
+	writeJSON(w, r, result)
+}
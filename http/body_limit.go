@@ -0,0 +1,39 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+)
+
+// BodyLimitMiddleware limita el tamaño del body de las requests POST; las que
+// declaran un Content-Length mayor se rechazan sin leerlas y el resto falla al
+// leer más allá del límite
+func BodyLimitMiddleware(limit int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.ContentLength > limit {
+			writeBodyTooLarge(w, r, limit)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeBodyTooLarge(w http.ResponseWriter, r *http.Request, limit int64) {
+	writeError(w, r, http.StatusRequestEntityTooLarge, codeRequestTooLarge, limit)
+}
+
+// writeBodyError responde 413 si el body excedió el límite y 400 en otro caso
+func writeBodyError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeBodyTooLarge(w, r, maxBytesErr.Limit)
+		return
+	}
+	writeError(w, r, http.StatusBadRequest, codeInvalidRequestBody)
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"loan-agent/domain"
+	"loan-agent/service"
+)
+
+type ConsolidationHandler struct {
+	service *service.ConsolidationService
+}
+
+func NewConsolidationHandler(service *service.ConsolidationService) *ConsolidationHandler {
+	return &ConsolidationHandler{service: service}
+}
+
+func (h *ConsolidationHandler) CompareConsolidation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
+		return
+	}
+
+	var input domain.ConsolidationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		slog.ErrorContext(r.Context(), "decoding request body", "error", err)
+		writeBodyError(w, r, err)
+		return
+	}
+	if input.Language == "" {
+		input.Language = preferredLanguage(r)
+	}
+
+	result, err := h.service.CompareConsolidation(input)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "comparing consolidation", "error", err)
+		writeServiceError(w, r, err, input.Language)
+		return
+	}
+
+	writeJSON(w, r, result)
+}
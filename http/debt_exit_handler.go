@@ -2,8 +2,9 @@ package http
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -12,49 +13,87 @@ import (
 )
 
 type DebtExitHandler struct {
-	service *service.DebtExitService
+	service   *service.DebtExitService
+	analytics *service.AnalyticsService
 }
 
-func NewDebtExitHandler(service *service.DebtExitService) *DebtExitHandler {
-	return &DebtExitHandler{service: service}
+func NewDebtExitHandler(
+	service *service.DebtExitService,
+	analytics *service.AnalyticsService,
+) *DebtExitHandler {
+	return &DebtExitHandler{service: service, analytics: analytics}
 }
 
 func (h *DebtExitHandler) CalculateDebtExitPlan(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
 		return
 	}
 
 	contentType := r.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "application/json") {
-		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
 		return
 	}
 
 	var input domain.DebtExitInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		log.Printf("Error decoding request body: %v", err)
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "decoding request body", "error", err)
+		writeBodyError(w, r, err)
 		return
 	}
+	if input.Language == "" {
+		input.Language = preferredLanguage(r)
+	}
 
 	result, err := h.service.CalculateDebtExitPlan(input)
 	if err != nil {
-		log.Printf("Error calculating debt exit plan: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "calculating debt exit plan", "error", err)
+		writeServiceError(w, r, err, input.Language)
 		return
 	}
 
+	h.analytics.RecordStrategy(input.Strategy)
+
 	// Codificar JSON en buffer primero para evitar escribir header si falla
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(result); err != nil {
-		log.Printf("Error encoding response: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "encoding response", "error", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if _, err := buf.WriteTo(w); err != nil {
-		log.Printf("Error writing response: %v", err)
+		slog.ErrorContext(r.Context(), "writing response", "error", err)
 	}
 }
+
+func (h *DebtExitHandler) SolveTargetPayoff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
+		return
+	}
+
+	var input domain.TargetPayoffInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		slog.ErrorContext(r.Context(), "decoding request body", "error", err)
+		writeBodyError(w, r, err)
+		return
+	}
+
+	result, err := h.service.SolveTargetPayoff(input)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "solving target payoff", "error", err)
+		writeServiceError(w, r, err, cmp.Or(input.Language, preferredLanguage(r)))
+		return
+	}
+
+	writeJSON(w, r, result)
+}
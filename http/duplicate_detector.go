@@ -0,0 +1,213 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"loan-agent/clock"
+	"loan-agent/repository"
+	"loan-agent/service"
+)
+
+const duplicateCleanupInterval = time.Minute
+
+// cachedResponse es la última respuesta exitosa a una request idéntica
+type cachedResponse struct {
+	requestID   string // request ID de la respuesta original
+	body        []byte
+	contentType string
+	storedAt    time.Time
+	age         time.Duration // antigüedad al momento de devolverla
+	repeats     int           // veces que se devolvió desde la caché
+}
+
+// DuplicateDetector reconoce cuando un cliente repite la misma request (misma
+// ruta y mismo cuerpo) dentro de una ventana corta y devuelve la respuesta
+// anterior en lugar de recalcularla
+type DuplicateDetector struct {
+	mu          sync.Mutex
+	window      time.Duration
+	responses   map[string]*cachedResponse
+	encryptor   *repository.FieldEncryptor
+	clock       clock.Clock
+	stopCleanup chan struct{}
+}
+
+func NewDuplicateDetector(window time.Duration) *DuplicateDetector {
+	d := &DuplicateDetector{
+		window:      window,
+		responses:   make(map[string]*cachedResponse),
+		clock:       clock.Real{},
+		stopCleanup: make(chan struct{}),
+	}
+	go d.cleanupLoop()
+	return d
+}
+
+// SetClock reemplaza el reloj usado para vencer las respuestas guardadas
+func (d *DuplicateDetector) SetClock(c clock.Clock) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clock = c
+}
+
+// SetEncryptor cifra los cuerpos guardados; pueden contener datos personales
+// como los nombres de las deudas
+func (d *DuplicateDetector) SetEncryptor(encryptor *repository.FieldEncryptor) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.encryptor = encryptor
+}
+
+func (d *DuplicateDetector) cleanupLoop() {
+	ticker := time.NewTicker(duplicateCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.cleanup()
+		case <-d.stopCleanup:
+			return
+		}
+	}
+}
+
+func (d *DuplicateDetector) cleanup() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.clock.Now()
+	for key, response := range d.responses {
+		if now.Sub(response.storedAt) > d.window {
+			delete(d.responses, key)
+		}
+	}
+}
+
+func (d *DuplicateDetector) Stop() {
+	close(d.stopCleanup)
+}
+
+// lookup devuelve una copia de la respuesta guardada si sigue dentro de la ventana
+func (d *DuplicateDetector) lookup(key string) (cachedResponse, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	response, ok := d.responses[key]
+	if !ok {
+		return cachedResponse{}, false
+	}
+	age := d.clock.Now().Sub(response.storedAt)
+	if age > d.window {
+		return cachedResponse{}, false
+	}
+	copied := *response
+	if d.encryptor != nil {
+		body, err := d.encryptor.Decrypt(string(response.body))
+		if err != nil {
+			slog.Warn("failed to decrypt duplicate response", "error", err)
+			return cachedResponse{}, false
+		}
+		copied.body = []byte(body)
+	}
+	response.repeats++
+	copied.repeats = response.repeats
+	copied.age = age
+	return copied, true
+}
+
+func (d *DuplicateDetector) store(key, requestID string, body []byte, contentType string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.encryptor != nil {
+		encrypted, err := d.encryptor.Encrypt(string(body))
+		if err != nil {
+			slog.Warn("failed to encrypt duplicate response", "error", err)
+			return
+		}
+		body = []byte(encrypted)
+	}
+	d.responses[key] = &cachedResponse{
+		requestID:   requestID,
+		body:        body,
+		contentType: contentType,
+		storedAt:    d.clock.Now(),
+	}
+}
+
+// bodyRecorder captura el status y el cuerpo escritos por el handler
+type bodyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bodyRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// DuplicateRequestMiddleware responde las requests POST idénticas de un mismo
+// cliente con la respuesta anterior, marcada con los headers Warning y
+// X-Duplicate-Request para que el cliente corrija su ciclo de reintentos.
+// X-Duplicate-Of es el X-Request-ID de la request original, el mismo que
+// aparece en su log.
+func DuplicateRequestMiddleware(
+	detector *DuplicateDetector,
+	next http.Handler,
+) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeBodyError(w, r, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		// Accept-Language elige el idioma de la respuesta: el mismo body en otro
+		// idioma no es un duplicado; tampoco el de otro usuario detrás de la misma
+		// IP ni el de una API key sandbox, cuyo cálculo no se guardó
+		fmt.Fprintf(hash, "%s\n%s\n%s\n%s\n%t\n", extractClientIP(r), UserIDFromContext(r.Context()), r.URL.Path, r.Header.Get("Accept-Language"), service.IsSandbox(r.Context()))
+		hash.Write(body)
+		key := hex.EncodeToString(hash.Sum(nil))
+
+		if cached, ok := detector.lookup(key); ok {
+			slog.InfoContext(r.Context(), "duplicate request", "client_ip", extractClientIP(r), "route", r.URL.Path, "repeat", cached.repeats)
+			w.Header().Set("Content-Type", cached.contentType)
+			w.Header().Set("Warning", `199 loan-agent "solicitud idéntica repetida; se devuelve el resultado anterior"`)
+			w.Header().Set("X-Duplicate-Request", strconv.Itoa(cached.repeats))
+			w.Header().Set("X-Duplicate-Of", cached.requestID)
+			w.Header().Set("Age", strconv.Itoa(int(cached.age.Seconds())))
+			w.Write(cached.body)
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		if recorder.status == http.StatusOK {
+			detector.store(key, RequestIDFromContext(r.Context()), recorder.body.Bytes(), recorder.Header().Get("Content-Type"))
+		}
+	})
+}
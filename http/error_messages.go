@@ -0,0 +1,64 @@
+package http
+
+import (
+	"fmt"
+
+	"loan-agent/service"
+)
+
+// responseMessages contiene los mensajes de los errores de la capa HTTP por
+// idioma, indexados por código; los errores de los servicios traen su propio
+// catálogo (ver service.LocalizedMessage). Cada idioma debe definir las mismas
+// claves con los mismos verbos de formato.
+var responseMessages = map[string]map[string]string{
+	"es": {
+		codeDraining:                 "el servidor se está deteniendo",
+		codeHookRejected:             "solicitud rechazada: %s",
+		codeIdempotencyKeyInProgress: "una solicitud con esta Idempotency-Key aún está en curso",
+		codeIdempotencyKeyReused:     "la Idempotency-Key ya se usó con un cuerpo de solicitud distinto",
+		codeInternal:                 "error interno del servidor",
+		codeInvalidIdempotencyKey:    "la Idempotency-Key excede %d caracteres",
+		codeInvalidMaintenanceWindow: "EndsAt debe ser posterior a StartsAt",
+		codeInvalidRateLimitConfig:   "%s",
+		codeInvalidRequestBody:       "cuerpo de la solicitud inválido",
+		codeInvalidTimeRange:         "from/to deben ser fechas RFC 3339",
+		codeMaintenance:              "servicio en mantenimiento",
+		codeMethodNotAllowed:         "método no permitido",
+		codeRateLimited:              "límite de solicitudes excedido",
+		codeRequestTooLarge:          "cuerpo de la solicitud demasiado grande: el límite es %d bytes",
+		codeUnauthorized:             "no autorizado",
+		codeUnsupportedMediaType:     "el Content-Type debe ser application/json",
+	},
+	"en": {
+		codeDraining:                 "draining",
+		codeHookRejected:             "request rejected: %s",
+		codeIdempotencyKeyInProgress: "a request with this Idempotency-Key is still in progress",
+		codeIdempotencyKeyReused:     "Idempotency-Key was already used with a different request body",
+		codeInternal:                 "internal server error",
+		codeInvalidIdempotencyKey:    "Idempotency-Key exceeds %d characters",
+		codeInvalidMaintenanceWindow: "EndsAt must be after StartsAt",
+		codeInvalidRateLimitConfig:   "%s",
+		codeInvalidRequestBody:       "invalid request body",
+		codeInvalidTimeRange:         "from/to must be RFC 3339 timestamps",
+		codeMaintenance:              "service under maintenance",
+		codeMethodNotAllowed:         "method not allowed",
+		codeRateLimited:              "rate limit exceeded",
+		codeRequestTooLarge:          "request body too large: limit is %d bytes",
+		codeUnauthorized:             "unauthorized",
+		codeUnsupportedMediaType:     "Content-Type must be application/json",
+	},
+}
+
+// responseText formatea el mensaje del código en el idioma pedido, o en el
+// idioma por defecto si el idioma no está en el catálogo
+func responseText(lang, code string, args ...any) string {
+	messages, ok := responseMessages[lang]
+	if !ok {
+		messages = responseMessages[service.DefaultLanguage]
+	}
+	template, ok := messages[code]
+	if !ok {
+		return code
+	}
+	return fmt.Sprintf(template, args...)
+}
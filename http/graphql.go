@@ -0,0 +1,637 @@
+package http
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"loan-agent/domain"
+	"loan-agent/service"
+)
+
+// maxGraphQLRootFields limita los cálculos por request: cada campo raíz es un
+// cálculo completo y consume un token del rate limit del grupo
+const maxGraphQLRootFields = 5
+
+// graphqlRequest es el body de POST /graphql
+type graphqlRequest struct {
+	Query         string          `json:"query"`
+	OperationName string          `json:"operationName"`
+	Variables     json.RawMessage `json:"variables"`
+}
+
+type graphqlError struct {
+	Message    string         `json:"message"`
+	Path       []string       `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   *graphqlObject `json:"data,omitempty"`
+	Errors []graphqlError `json:"errors,omitempty"`
+}
+
+// graphqlObject es un objeto de la respuesta con las claves en el orden de la selección
+type graphqlObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func newGraphQLObject() *graphqlObject {
+	return &graphqlObject{values: map[string]any{}}
+}
+
+func (o *graphqlObject) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *graphqlObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// errGraphQLInternal marca los errores que no son de la entrada; el detalle
+// solo va al log
+var errGraphQLInternal = errors.New("internal server error")
+
+// graphqlField es un campo raíz del tipo Query: los argumentos que acepta, el
+// tipo Go de su resultado (para validar la selección) y cómo resolverlo
+type graphqlField struct {
+	Arguments []string
+	Result    reflect.Type
+	Resolve   func(r *http.Request, args map[string]any) (any, string, error)
+}
+
+// GraphQLHandler expone los calculadores como campos del tipo Query para que
+// los front-ends pidan solo los campos que usan. Los tipos y nombres de campo
+// son los mismos de la API REST; no hay introspección. No hay mutaciones: los
+// calculadores solo leen su entrada y devuelven un resultado, sin modificar
+// recursos del cliente, así que todos son campos de Query.
+type GraphQLHandler struct {
+	fields  map[string]graphqlField
+	limiter *RateLimiter
+}
+
+func NewGraphQLHandler(
+	loanService *service.LoanService,
+	termRecommendationService *service.TermRecommendationService,
+	debtExitService *service.DebtExitService,
+	consolidationService *service.ConsolidationService,
+	balanceTransferService *service.BalanceTransferService,
+	paymentAllocationService *service.PaymentAllocationService,
+	rateChangeService *service.RateChangeService,
+	analytics *service.AnalyticsService,
+	limiter *RateLimiter,
+) *GraphQLHandler {
+	input := []string{"input"}
+	return &GraphQLHandler{limiter: limiter, fields: map[string]graphqlField{
+		"calculateLoan": {
+			Arguments: input,
+			Result:    reflect.TypeFor[domain.LoanResult](),
+			Resolve: func(r *http.Request, args map[string]any) (any, string, error) {
+				var input domain.LoanInput
+				if err := decodeGraphQLInput(args, &input); err != nil {
+					return nil, "", err
+				}
+				if input.Language == "" {
+					input.Language = preferredLanguage(r)
+				}
+				input.UserID = UserIDFromContext(r.Context())
+				input.Sandbox = service.IsSandbox(r.Context())
+				result, err := loanService.CalculateLoan(input)
+				if err == nil {
+					analytics.RecordLoanAmount(input.Amount)
+				}
+				return result, input.Language, err
+			},
+		},
+		"recommendTerm": {
+			Arguments: input,
+			Result:    reflect.TypeFor[domain.TermRecommendationResult](),
+			Resolve: func(r *http.Request, args map[string]any) (any, string, error) {
+				var input domain.TermRecommendationInput
+				if err := decodeGraphQLInput(args, &input); err != nil {
+					return nil, "", err
+				}
+				if input.Language == "" {
+					input.Language = preferredLanguage(r)
+				}
+				result, err := termRecommendationService.RecommendTerm(input)
+				if err == nil {
+					analytics.RecordLoanAmount(input.Amount)
+				}
+				return result, input.Language, err
+			},
+		},
+		"debtExitPlan": {
+			Arguments: input,
+			Result:    reflect.TypeFor[domain.DebtExitResult](),
+			Resolve: func(r *http.Request, args map[string]any) (any, string, error) {
+				var input domain.DebtExitInput
+				if err := decodeGraphQLInput(args, &input); err != nil {
+					return nil, "", err
+				}
+				if input.Language == "" {
+					input.Language = preferredLanguage(r)
+				}
+				result, err := debtExitService.CalculateDebtExitPlan(input)
+				if err == nil {
+					analytics.RecordStrategy(input.Strategy)
+				}
+				return result, input.Language, err
+			},
+		},
+		"debtExitTarget": {
+			Arguments: input,
+			Result:    reflect.TypeFor[domain.TargetPayoffResult](),
+			Resolve: func(r *http.Request, args map[string]any) (any, string, error) {
+				var input domain.TargetPayoffInput
+				if err := decodeGraphQLInput(args, &input); err != nil {
+					return nil, "", err
+				}
+				result, err := debtExitService.SolveTargetPayoff(input)
+				return result, cmp.Or(input.Language, preferredLanguage(r)), err
+			},
+		},
+		"consolidation": {
+			Arguments: input,
+			Result:    reflect.TypeFor[domain.ConsolidationResult](),
+			Resolve: func(r *http.Request, args map[string]any) (any, string, error) {
+				var input domain.ConsolidationInput
+				if err := decodeGraphQLInput(args, &input); err != nil {
+					return nil, "", err
+				}
+				if input.Language == "" {
+					input.Language = preferredLanguage(r)
+				}
+				result, err := consolidationService.CompareConsolidation(input)
+				return result, input.Language, err
+			},
+		},
+		"balanceTransfer": {
+			Arguments: input,
+			Result:    reflect.TypeFor[domain.BalanceTransferResult](),
+			Resolve: func(r *http.Request, args map[string]any) (any, string, error) {
+				var input domain.BalanceTransferInput
+				if err := decodeGraphQLInput(args, &input); err != nil {
+					return nil, "", err
+				}
+				if input.Language == "" {
+					input.Language = preferredLanguage(r)
+				}
+				result, err := balanceTransferService.AnalyzeBalanceTransfer(input)
+				return result, input.Language, err
+			},
+		},
+		"paymentAllocation": {
+			Arguments: input,
+			Result:    reflect.TypeFor[domain.PaymentAllocationResult](),
+			Resolve: func(r *http.Request, args map[string]any) (any, string, error) {
+				var input domain.PaymentAllocationInput
+				if err := decodeGraphQLInput(args, &input); err != nil {
+					return nil, "", err
+				}
+				result, err := paymentAllocationService.AllocatePayment(input)
+				return result, preferredLanguage(r), err
+			},
+		},
+		"rateChange": {
+			Arguments: input,
+			Result:    reflect.TypeFor[domain.RateChangeResult](),
+			Resolve: func(r *http.Request, args map[string]any) (any, string, error) {
+				var input domain.RateChangeInput
+				if err := decodeGraphQLInput(args, &input); err != nil {
+					return nil, "", err
+				}
+				if input.Language == "" {
+					input.Language = preferredLanguage(r)
+				}
+				result, err := rateChangeService.CompareRateChange(input)
+				return result, input.Language, err
+			},
+		},
+		"calculations": {
+			Arguments: []string{"tag"},
+			Result:    reflect.TypeFor[[]domain.LoanRecord](),
+			Resolve: func(r *http.Request, args map[string]any) (any, string, error) {
+				tag, ok := args["tag"].(string)
+				if !ok && args["tag"] != nil {
+					return nil, "", errors.New("argument tag must be a string")
+				}
+				records, err := loanService.ListCalculations(UserIDFromContext(r.Context()), tag)
+				if err != nil {
+					return nil, preferredLanguage(r), fmt.Errorf("%w: %w", errGraphQLInternal, err)
+				}
+				return records, preferredLanguage(r), nil
+			},
+		},
+		"tags": {
+			Result: reflect.TypeFor[[]domain.TagCount](),
+			Resolve: func(r *http.Request, args map[string]any) (any, string, error) {
+				tags, err := loanService.ListTags(UserIDFromContext(r.Context()))
+				if err != nil {
+					return nil, preferredLanguage(r), fmt.Errorf("%w: %w", errGraphQLInternal, err)
+				}
+				return tags, preferredLanguage(r), nil
+			},
+		},
+	}}
+}
+
+// decodeGraphQLInput convierte el argumento input al tipo de entrada del
+// calculador, con las mismas reglas que el body JSON de la API REST
+func decodeGraphQLInput(args map[string]any, target any) error {
+	data, err := json.Marshal(args["input"])
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("invalid input: %w", err)
+	}
+	return nil
+}
+
+// graphqlRequestError es un error del documento o de las variables: la
+// operación no se ejecuta y la respuesta es 400
+type graphqlRequestError struct {
+	message string
+	// code es el código de extensions; vacío es GRAPHQL_VALIDATION_FAILED
+	code string
+}
+
+func (e *graphqlRequestError) Error() string {
+	return e.message
+}
+
+func graphqlRequestErrorf(format string, args ...any) error {
+	return &graphqlRequestError{message: fmt.Sprintf(format, args...)}
+}
+
+func (h *GraphQLHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
+		return
+	}
+
+	var request graphqlRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.WarnContext(r.Context(), "decoding request body", "error", err)
+		writeBodyError(w, r, err)
+		return
+	}
+
+	operation, variables, err := h.prepare(request)
+	if err != nil {
+		slog.InfoContext(r.Context(), "rejecting graphql document", "error", err)
+		code := "GRAPHQL_VALIDATION_FAILED"
+		var requestErr *graphqlRequestError
+		if errors.As(err, &requestErr) && requestErr.code != "" {
+			code = requestErr.code
+		}
+		writeGraphQLResponse(w, r, http.StatusBadRequest, graphqlResponse{
+			Errors: []graphqlError{{Message: err.Error(), Extensions: map[string]any{"code": code}}},
+		})
+		return
+	}
+
+	// RateLimitMiddleware ya cobró un token; cada calculador adicional cobra otro
+	if extra := countGraphQLRootFields(operation.Selections) - 1; extra > 0 {
+		status := h.limiter.AllowRequestN(RouteGroup(r.URL.Path), r.Header.Get(apiKeyHeader), extractClientIP(r), extra)
+		if !writeRateLimitStatus(w, r, status) {
+			return
+		}
+	}
+
+	response := graphqlResponse{Data: newGraphQLObject()}
+	for _, selection := range operation.Selections {
+		key := selection.responseKey()
+		if selection.Name == "__typename" {
+			response.Data.set(key, "Query")
+			continue
+		}
+
+		value, graphqlErr := h.resolve(r, selection, variables)
+		if graphqlErr != nil {
+			graphqlErr.Path = []string{key}
+			response.Errors = append(response.Errors, *graphqlErr)
+			response.Data.set(key, nil)
+			continue
+		}
+		response.Data.set(key, value)
+	}
+	writeGraphQLResponse(w, r, http.StatusOK, response)
+}
+
+// prepare elige la operación, resuelve las variables y valida la selección
+// contra los tipos de resultado antes de ejecutar cualquier cálculo
+func (h *GraphQLHandler) prepare(request graphqlRequest) (graphqlOperation, map[string]any, error) {
+	if strings.TrimSpace(request.Query) == "" {
+		return graphqlOperation{}, nil, graphqlRequestErrorf("query is required")
+	}
+	operations, err := parseGraphQL(request.Query)
+	if err != nil {
+		return graphqlOperation{}, nil, err
+	}
+
+	var operation graphqlOperation
+	switch {
+	case request.OperationName != "":
+		found := false
+		for _, candidate := range operations {
+			if candidate.Name == request.OperationName {
+				operation, found = candidate, true
+			}
+		}
+		if !found {
+			return graphqlOperation{}, nil, graphqlRequestErrorf("unknown operation %q", request.OperationName)
+		}
+	case len(operations) == 1:
+		operation = operations[0]
+	default:
+		return graphqlOperation{}, nil, graphqlRequestErrorf("operationName is required when the document has several operations")
+	}
+	if operation.Type != "query" {
+		// Los calculadores no modifican recursos: no hay mutaciones ni suscripciones
+		return graphqlOperation{}, nil, &graphqlRequestError{
+			message: fmt.Sprintf("%s operations are not supported: the calculators are read-only query fields", operation.Type),
+			code:    "GRAPHQL_OPERATION_NOT_SUPPORTED",
+		}
+	}
+
+	provided := map[string]any{}
+	if len(request.Variables) > 0 && string(request.Variables) != "null" {
+		decoder := json.NewDecoder(bytes.NewReader(request.Variables))
+		decoder.UseNumber()
+		if err := decoder.Decode(&provided); err != nil {
+			return graphqlOperation{}, nil, graphqlRequestErrorf("variables must be a JSON object")
+		}
+	}
+	variables := map[string]any{}
+	for _, variable := range operation.Variables {
+		value, ok := provided[variable.Name]
+		if !ok {
+			value = variable.DefaultValue
+		}
+		if value == nil && strings.HasSuffix(variable.Type, "!") {
+			return graphqlOperation{}, nil, graphqlRequestErrorf("variable $%s of type %s is required", variable.Name, variable.Type)
+		}
+		variables[variable.Name] = value
+	}
+
+	if err := checkResponseKeys("Query", operation.Selections); err != nil {
+		return graphqlOperation{}, nil, err
+	}
+	for _, selection := range operation.Selections {
+		if selection.Name == "__typename" {
+			continue
+		}
+		field, ok := h.fields[selection.Name]
+		if !ok {
+			return graphqlOperation{}, nil, graphqlRequestErrorf("cannot query field %q on type Query", selection.Name)
+		}
+		for argument, value := range selection.Arguments {
+			if !slices.Contains(field.Arguments, argument) {
+				return graphqlOperation{}, nil, graphqlRequestErrorf("unknown argument %q on field %q", argument, selection.Name)
+			}
+			if _, err := resolveValue(value, variables); err != nil {
+				return graphqlOperation{}, nil, graphqlRequestErrorf("%s", err.Error())
+			}
+		}
+		if err := validateGraphQLSelections(field.Result, selection.Name, selection.Selections); err != nil {
+			return graphqlOperation{}, nil, err
+		}
+	}
+	if countGraphQLRootFields(operation.Selections) > maxGraphQLRootFields {
+		return graphqlOperation{}, nil, graphqlRequestErrorf("a request can query at most %d calculators", maxGraphQLRootFields)
+	}
+	return operation, variables, nil
+}
+
+// countGraphQLRootFields cuenta los calculadores pedidos; __typename no calcula nada
+func countGraphQLRootFields(selections []graphqlSelection) int {
+	count := 0
+	for _, selection := range selections {
+		if selection.Name != "__typename" {
+			count++
+		}
+	}
+	return count
+}
+
+// resolve ejecuta un campo raíz; los errores del cálculo se reportan en el
+// campo sin cortar los demás, como indica GraphQL
+func (h *GraphQLHandler) resolve(r *http.Request, selection graphqlSelection, variables map[string]any) (any, *graphqlError) {
+	field := h.fields[selection.Name]
+	args := map[string]any{}
+	for argument, value := range selection.Arguments {
+		// Ya validados en prepare
+		args[argument], _ = resolveValue(value, variables)
+	}
+
+	result, lang, err := field.Resolve(r, args)
+	if err != nil {
+		// Los errores de la entrada son del cliente; solo los internos son fallas del servidor
+		level := slog.LevelWarn
+		if errors.Is(err, errGraphQLInternal) {
+			level = slog.LevelError
+		}
+		slog.Log(r.Context(), level, "resolving graphql field", "field", selection.Name, "error", err)
+		return nil, graphqlFieldError(err, lang)
+	}
+
+	// El resultado pasa por JSON para respetar las etiquetas json de los tipos
+	data, err := json.Marshal(result)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "encoding graphql field", "field", selection.Name, "error", err)
+		return nil, &graphqlError{Message: responseText(preferredLanguage(r), codeInternal), Extensions: map[string]any{"code": codeInternal}}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		slog.ErrorContext(r.Context(), "decoding graphql field", "field", selection.Name, "error", err)
+		return nil, &graphqlError{Message: responseText(preferredLanguage(r), codeInternal), Extensions: map[string]any{"code": codeInternal}}
+	}
+	return projectGraphQL(field.Result, value, selection.Selections), nil
+}
+
+// graphqlFieldError traduce el error de un calculador con el mismo código y
+// detalles que la respuesta de error de la API REST
+func graphqlFieldError(err error, lang string) *graphqlError {
+	if errors.Is(err, errGraphQLInternal) {
+		return &graphqlError{Message: responseText(lang, codeInternal), Extensions: map[string]any{"code": codeInternal}}
+	}
+	var fields *service.ValidationErrors
+	if errors.As(err, &fields) {
+		return &graphqlError{
+			Message:    service.LocalizedMessage(err, lang),
+			Extensions: map[string]any{"code": service.ErrorCode(err), "fields": fields.LocalizedFields(lang)},
+		}
+	}
+	if code := service.ErrorCode(err); code != "" {
+		return &graphqlError{Message: service.LocalizedMessage(err, lang), Extensions: map[string]any{"code": code}}
+	}
+	return &graphqlError{Message: err.Error(), Extensions: map[string]any{"code": codeInvalidInput}}
+}
+
+// graphqlFields devuelve los campos de un struct por su nombre JSON, con los
+// campos de los structs embebidos al mismo nivel (como los codifica encoding/json)
+func graphqlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for embeddedName, embeddedType := range graphqlFields(field.Type) {
+				fields[embeddedName] = embeddedType
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// graphqlObjectType quita punteros y listas; devuelve el struct y true si el
+// tipo es un objeto GraphQL, o false si es un escalar (incluye mapas y fechas)
+func graphqlObjectType(t reflect.Type) (reflect.Type, bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct && t != timeType
+}
+
+func validateGraphQLSelections(t reflect.Type, path string, selections []graphqlSelection) error {
+	objectType, isObject := graphqlObjectType(t)
+	if !isObject {
+		if len(selections) > 0 {
+			return graphqlRequestErrorf("field %q is a scalar and cannot have a selection", path)
+		}
+		return nil
+	}
+	if len(selections) == 0 {
+		return graphqlRequestErrorf("field %q of type %s must have a selection of subfields", path, componentName(objectType))
+	}
+
+	if err := checkResponseKeys(path, selections); err != nil {
+		return err
+	}
+	fields := graphqlFields(objectType)
+	for _, selection := range selections {
+		if len(selection.Arguments) > 0 {
+			return graphqlRequestErrorf("field %q does not take arguments", path+"."+selection.Name)
+		}
+		if selection.Name == "__typename" {
+			continue
+		}
+		fieldType, ok := fields[selection.Name]
+		if !ok {
+			return graphqlRequestErrorf("cannot query field %q on type %s", selection.Name, componentName(objectType))
+		}
+		if err := validateGraphQLSelections(fieldType, path+"."+selection.Name, selection.Selections); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkResponseKeys rechaza dos campos con la misma clave de respuesta; en vez
+// de combinarlos como permite GraphQL se pide un alias distinto
+func checkResponseKeys(path string, selections []graphqlSelection) error {
+	seen := map[string]bool{}
+	for _, selection := range selections {
+		key := selection.responseKey()
+		if seen[key] {
+			return graphqlRequestErrorf("field %q is selected twice in %s; use an alias", key, path)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// projectGraphQL recorta el valor JSON del resultado a los campos pedidos; la
+// selección ya se validó contra t
+func projectGraphQL(t reflect.Type, value any, selections []graphqlSelection) any {
+	if len(selections) == 0 || value == nil {
+		return value
+	}
+	if list, ok := value.([]any); ok {
+		projected := make([]any, len(list))
+		for i, item := range list {
+			projected[i] = projectGraphQL(t, item, selections)
+		}
+		return projected
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+
+	objectType, _ := graphqlObjectType(t)
+	fields := graphqlFields(objectType)
+	projected := newGraphQLObject()
+	for _, selection := range selections {
+		if selection.Name == "__typename" {
+			projected.set(selection.responseKey(), componentName(objectType))
+			continue
+		}
+		// Los campos omitempty ausentes del JSON se devuelven como null
+		projected.set(selection.responseKey(), projectGraphQL(fields[selection.Name], object[selection.Name], selection.Selections))
+	}
+	return projected
+}
+
+func writeGraphQLResponse(w http.ResponseWriter, r *http.Request, status int, response graphqlResponse) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "encoding graphql response", "error", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := buf.WriteTo(w); err != nil {
+		slog.ErrorContext(r.Context(), "writing response", "error", err)
+	}
+}
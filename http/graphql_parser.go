@@ -0,0 +1,560 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parser del subconjunto de GraphQL que atiende /graphql: operaciones con
+// variables, alias, argumentos y selecciones anidadas. Fragmentos y directivas
+// no se soportan y se rechazan con un error.
+
+// maxGraphQLDepth limita el anidamiento de selecciones, valores y tipos: el
+// parser es recursivo y un documento muy anidado agotaría el stack
+const maxGraphQLDepth = 32
+
+// graphqlSelection es un campo pedido en un selection set
+type graphqlSelection struct {
+	Alias      string
+	Name       string
+	Arguments  map[string]any
+	Selections []graphqlSelection
+}
+
+// responseKey es la clave del campo en la respuesta: el alias si se indicó
+func (s graphqlSelection) responseKey() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+type graphqlVariable struct {
+	Name         string
+	Type         string
+	DefaultValue any
+}
+
+type graphqlOperation struct {
+	Type       string // "query", "mutation" o "subscription"
+	Name       string
+	Variables  []graphqlVariable
+	Selections []graphqlSelection
+}
+
+// graphqlVariableRef es una variable usada como valor en el documento; los
+// valores se resuelven al ejecutar con resolveValue
+type graphqlVariableRef string
+
+type graphqlTokenKind int
+
+const (
+	tokenEOF graphqlTokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type graphqlToken struct {
+	Kind  graphqlTokenKind
+	Value string
+	Pos   int
+}
+
+// graphqlSyntaxError es un error del documento; Pos es el offset en bytes
+type graphqlSyntaxError struct {
+	Pos     int
+	Message string
+}
+
+func (e *graphqlSyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at offset %d: %s", e.Pos, e.Message)
+}
+
+func lexGraphQL(source string) ([]graphqlToken, error) {
+	var tokens []graphqlToken
+	for pos := 0; pos < len(source); {
+		c := source[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			pos++
+		case c == '#':
+			for pos < len(source) && source[pos] != '\n' && source[pos] != '\r' {
+				pos++
+			}
+		case strings.HasPrefix(source[pos:], "..."):
+			tokens = append(tokens, graphqlToken{Kind: tokenPunctuator, Value: "...", Pos: pos})
+			pos += 3
+		case strings.ContainsRune("!$&():=@[]{}|", rune(c)):
+			tokens = append(tokens, graphqlToken{Kind: tokenPunctuator, Value: string(c), Pos: pos})
+			pos++
+		case c == '_' || isLetter(c):
+			start := pos
+			for pos < len(source) && (source[pos] == '_' || isLetter(source[pos]) || isDigit(source[pos])) {
+				pos++
+			}
+			tokens = append(tokens, graphqlToken{Kind: tokenName, Value: source[start:pos], Pos: start})
+		case c == '-' || isDigit(c):
+			token, end, err := lexNumber(source, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token)
+			pos = end
+		case c == '"':
+			if strings.HasPrefix(source[pos:], `"""`) {
+				return nil, &graphqlSyntaxError{Pos: pos, Message: "block strings are not supported"}
+			}
+			value, end, err := lexString(source, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, graphqlToken{Kind: tokenString, Value: value, Pos: pos})
+			pos = end
+		default:
+			r, _ := utf8.DecodeRuneInString(source[pos:])
+			return nil, &graphqlSyntaxError{Pos: pos, Message: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	return append(tokens, graphqlToken{Kind: tokenEOF, Pos: len(source)}), nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func lexNumber(source string, start int) (graphqlToken, int, error) {
+	pos := start
+	if source[pos] == '-' {
+		pos++
+	}
+	digits := pos
+	for pos < len(source) && isDigit(source[pos]) {
+		pos++
+	}
+	if pos == digits {
+		return graphqlToken{}, 0, &graphqlSyntaxError{Pos: start, Message: "invalid number"}
+	}
+
+	kind := tokenInt
+	if pos < len(source) && source[pos] == '.' {
+		kind = tokenFloat
+		pos++
+		fraction := pos
+		for pos < len(source) && isDigit(source[pos]) {
+			pos++
+		}
+		if pos == fraction {
+			return graphqlToken{}, 0, &graphqlSyntaxError{Pos: start, Message: "invalid number"}
+		}
+	}
+	if pos < len(source) && (source[pos] == 'e' || source[pos] == 'E') {
+		kind = tokenFloat
+		pos++
+		if pos < len(source) && (source[pos] == '+' || source[pos] == '-') {
+			pos++
+		}
+		exponent := pos
+		for pos < len(source) && isDigit(source[pos]) {
+			pos++
+		}
+		if pos == exponent {
+			return graphqlToken{}, 0, &graphqlSyntaxError{Pos: start, Message: "invalid number"}
+		}
+	}
+	return graphqlToken{Kind: kind, Value: source[start:pos], Pos: start}, pos, nil
+}
+
+// lexString lee un string entre comillas; los escapes de GraphQL son los de
+// JSON, así que se decodifica con encoding/json
+func lexString(source string, start int) (string, int, error) {
+	pos := start + 1
+	for pos < len(source) {
+		switch source[pos] {
+		case '\\':
+			pos += 2
+		case '"':
+			var value string
+			if err := json.Unmarshal([]byte(source[start:pos+1]), &value); err != nil {
+				return "", 0, &graphqlSyntaxError{Pos: start, Message: "invalid string"}
+			}
+			return value, pos + 1, nil
+		case '\n', '\r':
+			return "", 0, &graphqlSyntaxError{Pos: start, Message: "unterminated string"}
+		default:
+			pos++
+		}
+	}
+	return "", 0, &graphqlSyntaxError{Pos: start, Message: "unterminated string"}
+}
+
+type graphqlParser struct {
+	tokens []graphqlToken
+	pos    int
+	depth  int
+}
+
+// parseGraphQL devuelve las operaciones del documento
+func parseGraphQL(source string) ([]graphqlOperation, error) {
+	tokens, err := lexGraphQL(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &graphqlParser{tokens: tokens}
+
+	var operations []graphqlOperation
+	for p.peek().Kind != tokenEOF {
+		operation, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+	if len(operations) == 0 {
+		return nil, &graphqlSyntaxError{Pos: 0, Message: "document has no operations"}
+	}
+	return operations, nil
+}
+
+// enter cuenta un nivel de anidamiento; cada enter exitoso va con un leave
+func (p *graphqlParser) enter() error {
+	if p.depth >= maxGraphQLDepth {
+		return &graphqlSyntaxError{Pos: p.peek().Pos, Message: fmt.Sprintf("document exceeds the maximum nesting depth of %d", maxGraphQLDepth)}
+	}
+	p.depth++
+	return nil
+}
+
+func (p *graphqlParser) leave() {
+	p.depth--
+}
+
+func (p *graphqlParser) peek() graphqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *graphqlParser) next() graphqlToken {
+	token := p.tokens[p.pos]
+	if token.Kind != tokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *graphqlParser) peekPunctuator(value string) bool {
+	token := p.peek()
+	return token.Kind == tokenPunctuator && token.Value == value
+}
+
+func (p *graphqlParser) expect(value string) error {
+	token := p.next()
+	if token.Kind != tokenPunctuator || token.Value != value {
+		return p.unexpected(token, fmt.Sprintf("expected %q", value))
+	}
+	return nil
+}
+
+func (p *graphqlParser) expectName() (string, error) {
+	token := p.next()
+	if token.Kind != tokenName {
+		return "", p.unexpected(token, "expected a name")
+	}
+	return token.Value, nil
+}
+
+func (p *graphqlParser) unexpected(token graphqlToken, message string) error {
+	if token.Kind == tokenEOF {
+		return &graphqlSyntaxError{Pos: token.Pos, Message: message + ", found end of document"}
+	}
+	return &graphqlSyntaxError{Pos: token.Pos, Message: fmt.Sprintf("%s, found %q", message, token.Value)}
+}
+
+func (p *graphqlParser) parseOperation() (graphqlOperation, error) {
+	if p.peekPunctuator("{") {
+		selections, err := p.parseSelectionSet()
+		return graphqlOperation{Type: "query", Selections: selections}, err
+	}
+
+	token := p.next()
+	if token.Kind != tokenName {
+		return graphqlOperation{}, p.unexpected(token, "expected an operation")
+	}
+	switch token.Value {
+	case "query", "mutation", "subscription":
+	case "fragment":
+		return graphqlOperation{}, &graphqlSyntaxError{Pos: token.Pos, Message: "fragments are not supported"}
+	default:
+		return graphqlOperation{}, p.unexpected(token, "expected an operation")
+	}
+
+	operation := graphqlOperation{Type: token.Value}
+	if p.peek().Kind == tokenName {
+		operation.Name = p.next().Value
+	}
+	if p.peekPunctuator("(") {
+		variables, err := p.parseVariableDefinitions()
+		if err != nil {
+			return graphqlOperation{}, err
+		}
+		operation.Variables = variables
+	}
+	if p.peekPunctuator("@") {
+		return graphqlOperation{}, &graphqlSyntaxError{Pos: p.peek().Pos, Message: "directives are not supported"}
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return graphqlOperation{}, err
+	}
+	operation.Selections = selections
+	return operation, nil
+}
+
+func (p *graphqlParser) parseVariableDefinitions() ([]graphqlVariable, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var variables []graphqlVariable
+	for !p.peekPunctuator(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		variableType, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		variable := graphqlVariable{Name: name, Type: variableType}
+		if p.peekPunctuator("=") {
+			p.next()
+			value, err := p.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+			variable.DefaultValue = value
+		}
+		variables = append(variables, variable)
+	}
+	p.next()
+	if len(variables) == 0 {
+		return nil, p.unexpected(p.tokens[p.pos-1], "expected a variable definition")
+	}
+	return variables, nil
+}
+
+// parseType lee una referencia de tipo (ej. "[LoanInput!]!"); solo se usa como texto
+func (p *graphqlParser) parseType() (string, error) {
+	if err := p.enter(); err != nil {
+		return "", err
+	}
+	defer p.leave()
+
+	var typeName string
+	if p.peekPunctuator("[") {
+		p.next()
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		typeName = "[" + inner + "]"
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return "", err
+		}
+		typeName = name
+	}
+	if p.peekPunctuator("!") {
+		p.next()
+		typeName += "!"
+	}
+	return typeName, nil
+}
+
+func (p *graphqlParser) parseSelectionSet() ([]graphqlSelection, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []graphqlSelection
+	for !p.peekPunctuator("}") {
+		if p.peekPunctuator("...") {
+			return nil, &graphqlSyntaxError{Pos: p.peek().Pos, Message: "fragments are not supported"}
+		}
+		selection, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	p.next()
+	if len(selections) == 0 {
+		return nil, p.unexpected(p.tokens[p.pos-1], "expected a field")
+	}
+	return selections, nil
+}
+
+func (p *graphqlParser) parseField() (graphqlSelection, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return graphqlSelection{}, err
+	}
+	selection := graphqlSelection{Name: name}
+	if p.peekPunctuator(":") {
+		p.next()
+		if selection.Name, err = p.expectName(); err != nil {
+			return graphqlSelection{}, err
+		}
+		selection.Alias = name
+	}
+
+	if p.peekPunctuator("(") {
+		p.next()
+		selection.Arguments = map[string]any{}
+		for !p.peekPunctuator(")") {
+			argument, err := p.expectName()
+			if err != nil {
+				return graphqlSelection{}, err
+			}
+			if err := p.expect(":"); err != nil {
+				return graphqlSelection{}, err
+			}
+			value, err := p.parseValue(false)
+			if err != nil {
+				return graphqlSelection{}, err
+			}
+			selection.Arguments[argument] = value
+		}
+		p.next()
+	}
+	if p.peekPunctuator("@") {
+		return graphqlSelection{}, &graphqlSyntaxError{Pos: p.peek().Pos, Message: "directives are not supported"}
+	}
+	if p.peekPunctuator("{") {
+		if selection.Selections, err = p.parseSelectionSet(); err != nil {
+			return graphqlSelection{}, err
+		}
+	}
+	return selection, nil
+}
+
+// parseValue lee un valor; constant indica que no se permiten variables
+// (valores por defecto de las variables)
+func (p *graphqlParser) parseValue(constant bool) (any, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	token := p.next()
+	switch token.Kind {
+	case tokenInt, tokenFloat:
+		return json.Number(token.Value), nil
+	case tokenString:
+		return token.Value, nil
+	case tokenName:
+		switch token.Value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		// Los enums se pasan como string: los campos enumerados de la API son strings
+		return token.Value, nil
+	case tokenPunctuator:
+		switch token.Value {
+		case "$":
+			if constant {
+				return nil, p.unexpected(token, "variables are not allowed here")
+			}
+			name, err := p.expectName()
+			return graphqlVariableRef(name), err
+		case "[":
+			list := []any{}
+			for !p.peekPunctuator("]") {
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			p.next()
+			return list, nil
+		case "{":
+			object := map[string]any{}
+			for !p.peekPunctuator("}") {
+				name, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				object[name] = value
+			}
+			p.next()
+			return object, nil
+		}
+	}
+	return nil, p.unexpected(token, "expected a value")
+}
+
+// resolveValue reemplaza las variables del valor por sus valores; las
+// variables sin valor ni valor por defecto son null
+func resolveValue(value any, variables map[string]any) (any, error) {
+	switch v := value.(type) {
+	case graphqlVariableRef:
+		resolved, ok := variables[string(v)]
+		if !ok {
+			return nil, fmt.Errorf("variable $%s is not defined", v)
+		}
+		return resolved, nil
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			resolved, err := resolveValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = resolved
+		}
+		return list, nil
+	case map[string]any:
+		object := make(map[string]any, len(v))
+		for key, item := range v {
+			resolved, err := resolveValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = resolved
+		}
+		return object, nil
+	}
+	return value, nil
+}
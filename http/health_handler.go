@@ -0,0 +1,44 @@
+package http
+
+import "net/http"
+
+type healthResponse struct {
+	Status      string
+	Maintenance *MaintenanceWindow `json:",omitempty"`
+}
+
+type HealthHandler struct {
+	maintenance *MaintenanceMode
+	readiness   *Readiness
+}
+
+func NewHealthHandler(maintenance *MaintenanceMode, readiness *Readiness) *HealthHandler {
+	return &HealthHandler{maintenance: maintenance, readiness: readiness}
+}
+
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, r, healthResponse{
+		Status:      "ok",
+		Maintenance: h.maintenance.Window(),
+	})
+}
+
+// Readyz falla con 503 mientras la instancia drena conexiones
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	if h.readiness.Draining() {
+		writeError(w, r, http.StatusServiceUnavailable, codeDraining)
+		return
+	}
+
+	writeJSON(w, r, healthResponse{Status: "ready"})
+}
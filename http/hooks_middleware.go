@@ -0,0 +1,115 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"loan-agent/service"
+)
+
+// bufferedResponse retiene la respuesta del handler para que los hooks la
+// puedan reemplazar antes de enviarla
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *bufferedResponse) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *bufferedResponse) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+// HooksMiddleware corre los hooks registrados alrededor del handler:
+// pre_validate sobre el cuerpo JSON de la request, post_calculate sobre las
+// respuestas exitosas y pre_respond sobre toda respuesta. Un veto responde 422.
+func HooksMiddleware(
+	hooks *service.HookRegistry,
+	next http.Handler,
+) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hooks.Has(service.HookPreValidate) && r.Body != nil {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeBodyError(w, r, err)
+				return
+			}
+			if json.Valid(body) {
+				payload, err := hooks.Run(r.Context(), service.HookEvent{
+					Stage:    service.HookPreValidate,
+					Endpoint: r.URL.Path,
+					Payload:  body,
+				})
+				if writeHookVeto(w, r, err) {
+					return
+				}
+				body = payload
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+
+		if !hooks.Has(service.HookPostCalculate) && !hooks.Has(service.HookPreRespond) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buffered := &bufferedResponse{header: w.Header(), status: http.StatusOK}
+		next.ServeHTTP(buffered, r)
+		body := buffered.body.Bytes()
+
+		isJSON := json.Valid(body)
+		stages := []service.HookStage{service.HookPreRespond}
+		if buffered.status == http.StatusOK {
+			stages = []service.HookStage{service.HookPostCalculate, service.HookPreRespond}
+		}
+		for _, stage := range stages {
+			// Las respuestas de error son texto plano; se envían como string JSON
+			payload := json.RawMessage(body)
+			if !isJSON {
+				payload, _ = json.Marshal(string(body))
+			}
+			result, err := hooks.Run(r.Context(), service.HookEvent{
+				Stage:    stage,
+				Endpoint: r.URL.Path,
+				Status:   buffered.status,
+				Payload:  payload,
+			})
+			if writeHookVeto(w, r, err) {
+				return
+			}
+			if !bytes.Equal(result, payload) {
+				body = result
+				isJSON = true
+				w.Header().Set("Content-Type", "application/json")
+			}
+		}
+
+		w.Header().Del("Content-Length")
+		w.WriteHeader(buffered.status)
+		if _, err := w.Write(body); err != nil {
+			slog.ErrorContext(r.Context(), "writing response", "error", err)
+		}
+	})
+}
+
+func writeHookVeto(w http.ResponseWriter, r *http.Request, err error) bool {
+	var veto *service.HookVetoError
+	if errors.As(err, &veto) {
+		writeError(w, r, http.StatusUnprocessableEntity, codeHookRejected, veto.Reason)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"loan-agent/clock"
+	"loan-agent/repository"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	maxIdempotencyKeyLength   = 255
+	idempotencyCacheKeyPrefix = "idempotency:"
+	idempotentReplayHeader    = "Idempotent-Replayed"
+)
+
+// idempotentResponse es la primera respuesta exitosa a una Idempotency-Key,
+// guardada en el cache para repetirla en los reintentos
+type idempotentResponse struct {
+	RequestHash string
+	Status      int
+	ContentType string
+	Body        []byte
+	StoredAt    time.Time
+}
+
+// IdempotencyStore guarda las respuestas por Idempotency-Key en el cache y
+// lleva las keys cuya primera request todavía se está atendiendo
+type IdempotencyStore struct {
+	cache repository.CacheRepository
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+func NewIdempotencyStore(cache repository.CacheRepository, ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		cache:    cache,
+		ttl:      ttl,
+		clock:    clock.Real{},
+		inFlight: make(map[string]bool),
+	}
+}
+
+// SetClock reemplaza el reloj usado para vencer las respuestas guardadas
+func (s *IdempotencyStore) SetClock(c clock.Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = c
+}
+
+func (s *IdempotencyStore) lookup(ctx context.Context, key string) (idempotentResponse, bool) {
+	cached, ok := s.cache.Get(key)
+	if !ok {
+		return idempotentResponse{}, false
+	}
+	var response idempotentResponse
+	if err := json.Unmarshal([]byte(cached), &response); err != nil {
+		slog.WarnContext(ctx, "ignoring invalid idempotent response", "key", key)
+		return idempotentResponse{}, false
+	}
+
+	s.mu.Lock()
+	expired := s.clock.Now().Sub(response.StoredAt) > s.ttl
+	s.mu.Unlock()
+	if expired {
+		return idempotentResponse{}, false
+	}
+	return response, true
+}
+
+func (s *IdempotencyStore) store(ctx context.Context, key string, response idempotentResponse) {
+	s.mu.Lock()
+	response.StoredAt = s.clock.Now()
+	s.mu.Unlock()
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to encode idempotent response", "error", err)
+		return
+	}
+	// Se guarda con vencimiento para que las keys elegidas por los clientes no
+	// se acumulen; no crítico si falla: el reintento se atiende como una request nueva
+	if err := s.cache.SetWithTTL(key, string(data), s.ttl); err != nil {
+		slog.WarnContext(ctx, "failed to store idempotent response", "error", err)
+	}
+}
+
+// claim marca la key como en curso; devuelve false si ya lo estaba
+func (s *IdempotencyStore) claim(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight[key] {
+		return false
+	}
+	s.inFlight[key] = true
+	return true
+}
+
+func (s *IdempotencyStore) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inFlight, key)
+}
+
+// idempotencyCacheKey acota la key al cliente (Authorization, X-API-Key) y a
+// la ruta, para que una key repetida por otro cliente no devuelva datos ajenos
+func idempotencyCacheKey(r *http.Request, idempotencyKey string) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s\n%s", r.URL.Path, r.Header.Get("Authorization"), r.Header.Get(apiKeyHeader), idempotencyKey)
+	return idempotencyCacheKeyPrefix + hex.EncodeToString(hash.Sum(nil))
+}
+
+// IdempotencyMiddleware atiende una sola vez cada POST con Idempotency-Key: la
+// primera respuesta exitosa se guarda y los reintentos la reciben sin volver a
+// calcular ni consumir el rate limit. Reusar la key con otro body es un error.
+func IdempotencyMiddleware(
+	store *IdempotencyStore,
+	next http.Handler,
+) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idempotencyKey := r.Header.Get(idempotencyKeyHeader)
+		if r.Method != http.MethodPost || idempotencyKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			writeError(w, r, http.StatusBadRequest, codeInvalidIdempotencyKey, maxIdempotencyKeyLength)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeBodyError(w, r, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		bodyHash := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(bodyHash[:])
+		key := idempotencyCacheKey(r, idempotencyKey)
+
+		if !store.claim(key) {
+			writeError(w, r, http.StatusConflict, codeIdempotencyKeyInProgress)
+			return
+		}
+		defer store.release(key)
+
+		if cached, ok := store.lookup(r.Context(), key); ok {
+			if cached.RequestHash != requestHash {
+				writeError(w, r, http.StatusUnprocessableEntity, codeIdempotencyKeyReused)
+				return
+			}
+			slog.InfoContext(r.Context(), "idempotent replay", "route", r.URL.Path)
+			w.Header().Set("Content-Type", cached.ContentType)
+			w.Header().Set(idempotentReplayHeader, "true")
+			w.WriteHeader(cached.Status)
+			w.Write(cached.Body)
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		// Solo se guardan las respuestas exitosas: un 429 o un error se puede reintentar
+		if recorder.status >= 200 && recorder.status < 300 {
+			store.store(r.Context(), key, idempotentResponse{
+				RequestHash: requestHash,
+				Status:      recorder.status,
+				ContentType: recorder.Header().Get("Content-Type"),
+				Body:        recorder.body.Bytes(),
+			})
+		}
+	})
+}
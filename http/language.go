@@ -0,0 +1,34 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"loan-agent/service"
+)
+
+// preferredLanguage elige, según el header Accept-Language, el idioma con
+// plantillas de explicación de mayor prioridad; vacío si ninguno coincide
+func preferredLanguage(r *http.Request) string {
+	best, bestQuality := "", 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		if quality > bestQuality && service.SupportsLanguage(lang) {
+			best, bestQuality = lang, quality
+		}
+	}
+
+	return best
+}
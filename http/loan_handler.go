@@ -3,7 +3,7 @@ package http
 import (
 	"bytes"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -12,49 +12,92 @@ import (
 )
 
 type LoanHandler struct {
-	service *service.LoanService
+	service   *service.LoanService
+	analytics *service.AnalyticsService
 }
 
-func NewLoanHandler(service *service.LoanService) *LoanHandler {
-	return &LoanHandler{service: service}
+func NewLoanHandler(
+	service *service.LoanService,
+	analytics *service.AnalyticsService,
+) *LoanHandler {
+	return &LoanHandler{service: service, analytics: analytics}
 }
 
 func (h *LoanHandler) CalculateLoan(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
 		return
 	}
 
 	contentType := r.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "application/json") {
-		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
 		return
 	}
 
 	var input domain.LoanInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		log.Printf("Error decoding request body: %v", err)
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "decoding request body", "error", err)
+		writeBodyError(w, r, err)
 		return
 	}
+	if input.Language == "" {
+		input.Language = preferredLanguage(r)
+	}
+	input.UserID = UserIDFromContext(r.Context())
+	input.Sandbox = service.IsSandbox(r.Context())
 
 	result, err := h.service.CalculateLoan(input)
 	if err != nil {
-		log.Printf("Error calculating loan: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "calculating loan", "error", err)
+		writeServiceError(w, r, err, input.Language)
 		return
 	}
 
+	h.analytics.RecordLoanAmount(input.Amount)
+
 	// Codificar JSON en buffer primero para evitar escribir header si falla
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(result); err != nil {
-		log.Printf("Error encoding response: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "encoding response", "error", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if _, err := buf.WriteTo(w); err != nil {
-		log.Printf("Error writing response: %v", err)
+		slog.ErrorContext(r.Context(), "writing response", "error", err)
+	}
+}
+
+func (h *LoanHandler) ListCalculations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	records, err := h.service.ListCalculations(UserIDFromContext(r.Context()), r.URL.Query().Get("tag"))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing loan calculations", "error", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal)
+		return
 	}
+
+	writeJSON(w, r, records)
+}
+
+func (h *LoanHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	tags, err := h.service.ListTags(UserIDFromContext(r.Context()))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing tags", "error", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal)
+		return
+	}
+
+	writeJSON(w, r, tags)
 }
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"loan-agent/clock"
+)
+
+const defaultMaintenanceRetryAfter = 5 * time.Minute
+
+// MaintenanceWindow describe una ventana de mantenimiento; StartsAt en el
+// futuro la programa y EndsAt vacío la deja abierta hasta que se desactive
+type MaintenanceWindow struct {
+	Message  string
+	StartsAt time.Time
+	EndsAt   *time.Time `json:",omitempty"`
+}
+
+type MaintenanceMode struct {
+	mu     sync.RWMutex
+	window *MaintenanceWindow
+	clock  clock.Clock
+}
+
+func NewMaintenanceMode() *MaintenanceMode {
+	return &MaintenanceMode{clock: clock.Real{}}
+}
+
+func (m *MaintenanceMode) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = c
+}
+
+// Now devuelve la hora según el reloj del modo mantenimiento
+func (m *MaintenanceMode) Now() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.clock.Now()
+}
+
+func (m *MaintenanceMode) Set(window MaintenanceWindow) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.window = &window
+}
+
+func (m *MaintenanceMode) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.window = nil
+}
+
+// Window devuelve la ventana configurada, descartándola si ya terminó
+func (m *MaintenanceMode) Window() *MaintenanceWindow {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.window != nil && m.window.EndsAt != nil && !m.clock.Now().Before(*m.window.EndsAt) {
+		m.window = nil
+	}
+	if m.window == nil {
+		return nil
+	}
+	window := *m.window
+	return &window
+}
+
+// maintenanceDetails acompaña al error MAINTENANCE con la ventana en curso
+type maintenanceDetails struct {
+	StartsAt time.Time
+	EndsAt   *time.Time `json:",omitempty"`
+}
+
+// MaintenanceMiddleware responde 503 a las requests de escritura durante el
+// mantenimiento; las GET/HEAD siguen atendiéndose. Antes de la ventana se
+// anuncia con X-Maintenance-Scheduled.
+func MaintenanceMiddleware(
+	maintenance *MaintenanceMode,
+	next http.Handler,
+) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		window := maintenance.Window()
+		if window == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		now := maintenance.Now()
+		if now.Before(window.StartsAt) {
+			w.Header().Set("X-Maintenance-Scheduled", window.StartsAt.UTC().Format(time.RFC3339))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		retryAfter := defaultMaintenanceRetryAfter
+		if window.EndsAt != nil {
+			retryAfter = window.EndsAt.Sub(now)
+		}
+
+		message := window.Message
+		if message == "" {
+			message = responseText(preferredLanguage(r), codeMaintenance)
+		}
+
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		writeErrorDetails(w, r, http.StatusServiceUnavailable, codeMaintenance, message, maintenanceDetails{
+			StartsAt: window.StartsAt,
+			EndsAt:   window.EndsAt,
+		})
+	})
+}
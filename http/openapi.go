@@ -0,0 +1,277 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"loan-agent/domain"
+)
+
+// apiOperation describe un endpoint documentado en la especificación OpenAPI
+type apiOperation struct {
+	Path        string
+	Summary     string
+	Description string
+	Request     reflect.Type
+	Response    reflect.Type
+}
+
+// documentedOperations son los endpoints públicos incluidos en /openapi.json
+var documentedOperations = []apiOperation{
+	{
+		Path:        "/loan/calculate",
+		Summary:     "Calcular un préstamo",
+		Description: "Cuota fija (sistema francés), seguros, tabla de amortización y evaluación de garantía.",
+		Request:     reflect.TypeFor[domain.LoanInput](),
+		Response:    reflect.TypeFor[domain.LoanResult](),
+	},
+	{
+		Path:        "/loan/recommend-term",
+		Summary:     "Recomendar un plazo",
+		Description: "Evalúa los plazos del rango y los ordena por score según la preferencia o los pesos indicados.",
+		Request:     reflect.TypeFor[domain.TermRecommendationInput](),
+		Response:    reflect.TypeFor[domain.TermRecommendationResult](),
+	},
+	{
+		Path:        "/loan/debt-exit-plan",
+		Summary:     "Plan de salida de deudas",
+		Description: "Simula el pago de la cartera de deudas con la estrategia elegida, mes a mes.",
+		Request:     reflect.TypeFor[domain.DebtExitInput](),
+		Response:    reflect.TypeFor[domain.DebtExitResult](),
+	},
+}
+
+// openAPISchemas genera los schemas JSON de los tipos Go a partir de sus
+// campos exportados y etiquetas json, registrando los structs como componentes
+type openAPISchemas struct {
+	components map[string]any
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+func (s *openAPISchemas) schema(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := s.schema(t.Elem())
+		schema["nullable"] = true
+		return schema
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		name := componentName(t)
+		if _, ok := s.components[name]; !ok {
+			// Reservar el nombre antes de recorrer los campos por si el tipo es recursivo
+			s.components[name] = nil
+			s.components[name] = s.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+
+	return map[string]any{}
+}
+
+func (s *openAPISchemas) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		// Los structs embebidos sin nombre aportan sus campos al nivel superior
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := s.structSchema(field.Type)
+			for key, value := range embedded["properties"].(map[string]any) {
+				properties[key] = value
+			}
+			if embeddedRequired, ok := embedded["required"].([]string); ok {
+				required = append(required, embeddedRequired...)
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = s.schema(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// componentName usa el nombre del tipo, en mayúscula inicial para los tipos no exportados
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// buildOpenAPISpec arma el documento OpenAPI 3 de los endpoints documentados
+func buildOpenAPISpec() map[string]any {
+	schemas := &openAPISchemas{components: map[string]any{}}
+	errorSchema := schemas.schema(reflect.TypeFor[errorResponse]())
+	jsonError := func(description string) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": errorSchema},
+			},
+		}
+	}
+
+	paths := map[string]any{}
+	for _, operation := range documentedOperations {
+		paths[operation.Path] = map[string]any{
+			"post": map[string]any{
+				"summary":     operation.Summary,
+				"description": operation.Description,
+				"parameters": []any{
+					map[string]any{
+						"name":        "Accept-Language",
+						"in":          "header",
+						"required":    false,
+						"description": "Idioma de las explicaciones y de los mensajes de error si el body no indica Language (es, en)",
+						"schema":      map[string]any{"type": "string"},
+					},
+					map[string]any{
+						"name":        "X-API-Key",
+						"in":          "header",
+						"required":    false,
+						"description": "API key de un tier de rate limit; sin key se aplica el límite por IP",
+						"schema":      map[string]any{"type": "string"},
+					},
+					map[string]any{
+						"name":        "Idempotency-Key",
+						"in":          "header",
+						"required":    false,
+						"description": "Los reintentos con la misma key reciben la primera respuesta exitosa sin recalcularla",
+						"schema":      map[string]any{"type": "string", "maxLength": maxIdempotencyKeyLength},
+					},
+				},
+				"requestBody": map[string]any{
+					"required": true,
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": schemas.schema(operation.Request)},
+					},
+				},
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "Resultado del cálculo",
+						"content": map[string]any{
+							"application/json": map[string]any{"schema": schemas.schema(operation.Response)},
+						},
+					},
+					"400": jsonError("Entrada inválida; code identifica la validación que falló"),
+					"401": jsonError("Falta el JWT de usuario o no es válido (si la autenticación está habilitada)"),
+					"405": jsonError("Método no permitido"),
+					"409": jsonError("Otra request con la misma Idempotency-Key está en curso"),
+					"413": jsonError("El body excede el tamaño máximo permitido"),
+					"415": jsonError("Content-Type debe ser application/json"),
+					"422": jsonError("Campos inválidos (code VALIDATION_FAILED, con cada campo y su código en details.fields), un hook de la institución rechazó la solicitud, o la Idempotency-Key se reusó con otro body"),
+					"429": jsonError("Límite de solicitudes excedido"),
+					"503": jsonError("Servicio en mantenimiento (code MAINTENANCE, con la ventana en details)"),
+				},
+			},
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Loan Agent API",
+			"version": "1.0.0",
+		},
+		"servers":    []any{map[string]any{"url": "/v1"}},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas.components},
+	}
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="es">
+<head>
+  <meta charset="utf-8">
+  <title>Loan Agent API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });</script>
+</body>
+</html>
+`
+
+// DocsHandler sirve la especificación OpenAPI y la documentación interactiva
+type DocsHandler struct {
+	spec []byte
+}
+
+// NewDocsHandler genera la especificación una sola vez al iniciar
+func NewDocsHandler() *DocsHandler {
+	spec, err := json.MarshalIndent(buildOpenAPISpec(), "", "  ")
+	if err != nil {
+		slog.Error("encoding OpenAPI spec", "error", err)
+	}
+	return &DocsHandler{spec: spec}
+}
+
+func (h *DocsHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(h.spec); err != nil {
+		slog.ErrorContext(r.Context(), "writing response", "error", err)
+	}
+}
+
+func (h *DocsHandler) Docs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write([]byte(swaggerUIPage)); err != nil {
+		slog.ErrorContext(r.Context(), "writing response", "error", err)
+	}
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"loan-agent/domain"
+	"loan-agent/service"
+)
+
+type PaymentAllocationHandler struct {
+	service *service.PaymentAllocationService
+}
+
+func NewPaymentAllocationHandler(service *service.PaymentAllocationService) *PaymentAllocationHandler {
+	return &PaymentAllocationHandler{service: service}
+}
+
+func (h *PaymentAllocationHandler) AllocatePayment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
+		return
+	}
+
+	var input domain.PaymentAllocationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		slog.ErrorContext(r.Context(), "decoding request body", "error", err)
+		writeBodyError(w, r, err)
+		return
+	}
+
+	result, err := h.service.AllocatePayment(input)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "allocating payment", "error", err)
+		writeServiceError(w, r, err, preferredLanguage(r))
+		return
+	}
+
+	writeJSON(w, r, result)
+}
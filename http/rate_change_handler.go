@@ -0,0 +1,51 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"loan-agent/domain"
+	"loan-agent/service"
+)
+
+type RateChangeHandler struct {
+	service *service.RateChangeService
+}
+
+func NewRateChangeHandler(service *service.RateChangeService) *RateChangeHandler {
+	return &RateChangeHandler{service: service}
+}
+
+func (h *RateChangeHandler) CompareRateChange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
+		return
+	}
+
+	var input domain.RateChangeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		slog.ErrorContext(r.Context(), "decoding request body", "error", err)
+		writeBodyError(w, r, err)
+		return
+	}
+	if input.Language == "" {
+		input.Language = preferredLanguage(r)
+	}
+
+	result, err := h.service.CompareRateChange(input)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "comparing rate change", "error", err)
+		writeServiceError(w, r, err, input.Language)
+		return
+	}
+
+	writeJSON(w, r, result)
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// apiKeyHeader identifica al cliente ante el rate limiter para aplicarle el
+// límite de su tier en lugar del límite por IP
+const apiKeyHeader = "X-API-Key"
+
+// RateLimitTier es el límite de un tier: Capacity requests por ventana. Las
+// API keys de un tier Sandbox reciben cálculos normales que no se guardan ni
+// disparan webhooks, para integrar contra producción sin efectos.
+type RateLimitTier struct {
+	Capacity      int
+	WindowSeconds float64
+	Sandbox       bool `json:",omitempty"`
+}
+
+func (t RateLimitTier) window() time.Duration {
+	return time.Duration(t.WindowSeconds * float64(time.Second))
+}
+
+// RateLimitConfig define los tiers (ej. "free", "partner") y el tier de cada
+// API key. Se carga al iniciar desde el secreto RATE_LIMIT_TIERS y se puede
+// reemplazar con el API de admin.
+type RateLimitConfig struct {
+	Tiers   map[string]RateLimitTier
+	APIKeys map[string]string
+}
+
+// ParseRateLimitConfig decodifica y valida la configuración de tiers en JSON
+func ParseRateLimitConfig(data []byte) (RateLimitConfig, error) {
+	var config RateLimitConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return RateLimitConfig{}, err
+	}
+	return config, config.Validate()
+}
+
+func (c RateLimitConfig) Validate() error {
+	for name, tier := range c.Tiers {
+		if tier.Capacity <= 0 || tier.WindowSeconds <= 0 {
+			return fmt.Errorf("tier %q must have a positive Capacity and WindowSeconds", name)
+		}
+	}
+	for key, tier := range c.APIKeys {
+		if key == "" {
+			return errors.New("API keys cannot be empty")
+		}
+		if _, ok := c.Tiers[tier]; !ok {
+			return fmt.Errorf("API key %s uses unknown tier %q", maskAPIKey(key), tier)
+		}
+	}
+	return nil
+}
+
+// RateLimitTierSummary describe un tier sin revelar las API keys que lo usan
+type RateLimitTierSummary struct {
+	Name          string
+	Capacity      int
+	WindowSeconds float64
+	Sandbox       bool `json:",omitempty"`
+	APIKeys       []string
+}
+
+// summary lista los tiers con las API keys enmascaradas
+func (c RateLimitConfig) summary() []RateLimitTierSummary {
+	summaries := make([]RateLimitTierSummary, 0, len(c.Tiers))
+	for name, tier := range c.Tiers {
+		keys := []string{}
+		for key, keyTier := range c.APIKeys {
+			if keyTier == name {
+				keys = append(keys, maskAPIKey(key))
+			}
+		}
+		sort.Strings(keys)
+		summaries = append(summaries, RateLimitTierSummary{
+			Name:          name,
+			Capacity:      tier.Capacity,
+			WindowSeconds: tier.WindowSeconds,
+			Sandbox:       tier.Sandbox,
+			APIKeys:       keys,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// maskAPIKey deja visibles solo los primeros caracteres de la key
+func maskAPIKey(key string) string {
+	const visible = 4
+	if len(key) <= visible {
+		return "****"
+	}
+	return key[:visible] + "****"
+}
@@ -1,41 +1,86 @@
 package http
 
 import (
+	"maps"
+	"strings"
 	"sync"
 	"time"
-)
 
-const (
-	bucketCleanupThreshold = 1 * time.Hour
-	cleanupInterval        = 30 * time.Minute
+	"loan-agent/clock"
 )
 
 type clientBucket struct {
 	tokens     int
 	lastRefill time.Time
+	window     time.Duration
+}
+
+// RateLimiterConfig configura el límite por IP y la limpieza de buckets
+type RateLimiterConfig struct {
+	Capacity int
+	Window   time.Duration
+	// CleanupInterval es cada cuánto se eliminan los buckets sin uso por más
+	// de BucketTTL (o de su ventana, si es más larga)
+	CleanupInterval time.Duration
+	BucketTTL       time.Duration
 }
 
 type RateLimiter struct {
-	mu          sync.Mutex
-	capacity    int
-	refillDur   time.Duration
-	clients     map[string]*clientBucket
-	stopCleanup chan struct{}
+	mu              sync.Mutex
+	capacity        int
+	refillDur       time.Duration
+	groups          map[string]RateLimitTier
+	cleanupInterval time.Duration
+	bucketTTL       time.Duration
+	clients         map[string]*clientBucket
+	clock           clock.Clock
+	stopCleanup     chan struct{}
+
+	// tiers por API key: la configuración de arranque y el override de admin
+	tiers         RateLimitConfig
+	startupTiers  RateLimitConfig
+	tiersOverride bool
 }
 
-func NewRateLimiter(capacity int, refillDur time.Duration) *RateLimiter {
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
 	rl := &RateLimiter{
-		capacity:    capacity,
-		refillDur:   refillDur,
-		clients:     make(map[string]*clientBucket),
-		stopCleanup: make(chan struct{}),
+		capacity:        config.Capacity,
+		refillDur:       config.Window,
+		groups:          make(map[string]RateLimitTier),
+		cleanupInterval: config.CleanupInterval,
+		bucketTTL:       config.BucketTTL,
+		clients:         make(map[string]*clientBucket),
+		clock:           clock.Real{},
+		stopCleanup:     make(chan struct{}),
 	}
 	go rl.cleanupLoop()
 	return rl
 }
 
+// SetGroupLimit fija el límite por IP de un grupo de rutas; cada grupo lleva
+// sus propios buckets
+func (r *RateLimiter) SetGroupLimit(group string, capacity int, window time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groups[group] = RateLimitTier{Capacity: capacity, WindowSeconds: window.Seconds()}
+}
+
+// GroupLimits devuelve el límite por IP de cada grupo de rutas
+func (r *RateLimiter) GroupLimits() map[string]RateLimitTier {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.groups)
+}
+
+// SetClock reemplaza el reloj usado para recargar y limpiar los buckets
+func (r *RateLimiter) SetClock(c clock.Clock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clock = c
+}
+
 func (r *RateLimiter) cleanupLoop() {
-	ticker := time.NewTicker(cleanupInterval)
+	ticker := time.NewTicker(r.cleanupInterval)
 	defer ticker.Stop()
 
 	for {
@@ -52,10 +97,10 @@ func (r *RateLimiter) cleanup() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	now := time.Now()
-	for ip, bucket := range r.clients {
-		if now.Sub(bucket.lastRefill) > bucketCleanupThreshold {
-			delete(r.clients, ip)
+	now := r.clock.Now()
+	for client, bucket := range r.clients {
+		if now.Sub(bucket.lastRefill) > max(r.bucketTTL, bucket.window) {
+			delete(r.clients, client)
 		}
 	}
 }
@@ -64,30 +109,120 @@ func (r *RateLimiter) Stop() {
 	close(r.stopCleanup)
 }
 
+// LoadTiers fija la configuración de tiers de arranque
+func (r *RateLimiter) LoadTiers(config RateLimitConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tiers = config
+	r.startupTiers = config
+	r.tiersOverride = false
+}
+
+// OverrideTiers reemplaza la configuración de tiers hasta ResetTiers
+func (r *RateLimiter) OverrideTiers(config RateLimitConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tiers = config
+	r.tiersOverride = true
+}
+
+// ResetTiers vuelve a la configuración de tiers de arranque
+func (r *RateLimiter) ResetTiers() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tiers = r.startupTiers
+	r.tiersOverride = false
+}
+
+// Tiers devuelve los tiers vigentes (con las API keys enmascaradas) e indica
+// si vienen de un override de admin
+func (r *RateLimiter) Tiers() ([]RateLimitTierSummary, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tiers.summary(), r.tiersOverride
+}
+
+// SandboxKey indica si la API key pertenece a un tier sandbox
+func (r *RateLimiter) SandboxKey(apiKey string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tierName, ok := r.tiers.APIKeys[apiKey]
+	return ok && apiKey != "" && r.tiers.Tiers[tierName].Sandbox
+}
+
+// RateLimitStatus es el resultado de consumir un request del bucket del cliente
+type RateLimitStatus struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset es cuando el bucket vuelve a llenarse
+	Reset time.Time
+	// RetryAfter es cuánto esperar antes de reintentar un request rechazado
+	RetryAfter time.Duration
+}
+
+// Allow aplica el límite por IP
 func (r *RateLimiter) Allow(ip string) bool {
+	return r.AllowRequest("", "", ip).Allowed
+}
+
+// AllowRequest aplica, dentro del grupo de rutas, el límite del tier de la API
+// key con un bucket por key; sin key o con una key desconocida aplica el límite
+// por IP del grupo
+func (r *RateLimiter) AllowRequest(group, apiKey, ip string) RateLimitStatus {
+	return r.AllowRequestN(group, apiKey, ip, 1)
+}
+
+// AllowRequestN es AllowRequest para una request que cuesta n tokens; se
+// consumen todos o ninguno
+func (r *RateLimiter) AllowRequestN(group, apiKey, ip string, n int) RateLimitStatus {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	now := time.Now()
-	bucket, exists := r.clients[ip]
+	client, capacity, refillDur := ip, r.capacity, r.refillDur
+	if limit, ok := r.groups[group]; ok {
+		capacity, refillDur = limit.Capacity, limit.window()
+	}
+	if tierName, ok := r.tiers.APIKeys[apiKey]; ok && apiKey != "" {
+		tier := r.tiers.Tiers[tierName]
+		client, capacity, refillDur = "key:"+apiKey, tier.Capacity, tier.window()
+	}
+	client = group + "|" + client
+
+	now := r.clock.Now()
+	bucket, exists := r.clients[client]
 
 	if !exists {
-		r.clients[ip] = &clientBucket{
-			tokens:     r.capacity - 1,
-			lastRefill: now,
-		}
-		return true
+		bucket = &clientBucket{tokens: capacity, lastRefill: now, window: refillDur}
+		r.clients[client] = bucket
 	}
 
-	if now.Sub(bucket.lastRefill) >= r.refillDur {
-		bucket.tokens = r.capacity
+	if now.Sub(bucket.lastRefill) >= refillDur {
+		bucket.tokens = capacity
 		bucket.lastRefill = now
+		bucket.window = refillDur
 	}
+	// Si el tier bajó su capacidad, el bucket no conserva los tokens de más
+	bucket.tokens = min(bucket.tokens, capacity)
 
-	if bucket.tokens <= 0 {
-		return false
+	status := RateLimitStatus{
+		Limit: capacity,
+		Reset: bucket.lastRefill.Add(refillDur),
+	}
+	if bucket.tokens >= n {
+		bucket.tokens -= n
+		status.Allowed = true
+	} else {
+		status.RetryAfter = max(status.Reset.Sub(now), time.Second)
 	}
+	status.Remaining = bucket.tokens
+	return status
+}
 
-	bucket.tokens--
-	return true
+// RouteGroup devuelve el grupo de rutas de un path: su primer segmento
+// ("/loan/calculate" pertenece al grupo "loan")
+func RouteGroup(path string) string {
+	group, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return group
 }
@@ -1,9 +1,11 @@
 package http
 
 import (
-	"log"
+	"log/slog"
+	"math"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -33,36 +35,54 @@ func extractClientIP(r *http.Request) string {
 		if net.ParseIP(r.RemoteAddr) != nil {
 			return r.RemoteAddr
 		}
-		log.Printf("Warning: failed to parse RemoteAddr '%s': %v, using as-is", r.RemoteAddr, err)
+		slog.WarnContext(r.Context(), "failed to parse RemoteAddr, using as-is", "remote_addr", r.RemoteAddr, "error", err)
 		return r.RemoteAddr
 	}
 
 	if ip == "" {
-		log.Printf("Warning: empty IP from RemoteAddr '%s', using RemoteAddr", r.RemoteAddr)
+		slog.WarnContext(r.Context(), "empty IP in RemoteAddr, using RemoteAddr", "remote_addr", r.RemoteAddr)
 		return r.RemoteAddr
 	}
 
 	if net.ParseIP(ip) == nil {
-		log.Printf("Warning: invalid IP '%s' from RemoteAddr '%s', using RemoteAddr", ip, r.RemoteAddr)
+		slog.WarnContext(r.Context(), "invalid IP in RemoteAddr, using RemoteAddr", "ip", ip, "remote_addr", r.RemoteAddr)
 		return r.RemoteAddr
 	}
 
 	return ip
 }
 
+// RateLimitMiddleware aplica el límite del grupo de rutas (ver RouteGroup)
 func RateLimitMiddleware(
 	limiter *RateLimiter,
+	group string,
 	next http.Handler,
 ) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ip := extractClientIP(r)
-
-		if !limiter.Allow(ip) {
-			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		status := limiter.AllowRequest(group, r.Header.Get(apiKeyHeader), ip)
+		if !writeRateLimitStatus(w, r, status) {
 			return
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// writeRateLimitStatus escribe los headers del límite y, si la request no se
+// permite, responde 429; devuelve si la request puede continuar
+func writeRateLimitStatus(w http.ResponseWriter, r *http.Request, status RateLimitStatus) bool {
+	// Los headers indican al cliente cuándo se reinicia su ventana para que
+	// no reintente a ciegas
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(status.Reset.Unix(), 10))
+
+	if !status.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(status.RetryAfter.Seconds()))))
+		writeError(w, r, http.StatusTooManyRequests, codeRateLimited)
+		return false
+	}
+	return true
+}
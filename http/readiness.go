@@ -0,0 +1,61 @@
+package http
+
+import (
+	"sync"
+	"time"
+
+	"loan-agent/clock"
+)
+
+// Readiness controla si la instancia debe recibir tráfico nuevo. Al iniciar
+// el drenado /readyz falla para que el balanceador retire la instancia, pero
+// las requests siguen atendiéndose hasta el apagado.
+type Readiness struct {
+	mu          sync.Mutex
+	drainPeriod time.Duration
+	drainStart  time.Time
+	clock       clock.Clock
+}
+
+func NewReadiness(drainPeriod time.Duration) *Readiness {
+	return &Readiness{drainPeriod: drainPeriod, clock: clock.Real{}}
+}
+
+func (r *Readiness) SetClock(c clock.Clock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clock = c
+}
+
+// StartDrain marca la instancia como no lista; llamadas repetidas no reinician el periodo
+func (r *Readiness) StartDrain() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.drainStart.IsZero() {
+		r.drainStart = r.clock.Now()
+	}
+}
+
+func (r *Readiness) Draining() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.drainStart.IsZero()
+}
+
+// DrainRemaining devuelve cuánto falta para completar el periodo de drenado
+func (r *Readiness) DrainRemaining() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.drainStart.IsZero() {
+		return r.drainPeriod
+	}
+	return max(0, r.drainPeriod-r.clock.Now().Sub(r.drainStart))
+}
+
+// WaitForDrain inicia el drenado si no había empezado y espera a que termine
+func (r *Readiness) WaitForDrain() {
+	r.StartDrain()
+	time.Sleep(r.DrainRemaining())
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	requestIDHeader       = "X-Request-ID"
+	maxRequestIDLength    = 128
+	generatedRequestIDLen = 16
+)
+
+type requestIDKey struct{}
+
+// RequestIDFromContext devuelve el request ID asignado por RequestIDMiddleware
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID acepta IDs de clientes o proxies con caracteres seguros para logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, generatedRequestIDLen)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// RequestIDMiddleware asigna un request ID a cada request (respetando el
+// X-Request-ID entrante si es válido), lo devuelve en la respuesta y registra
+// un log estructurado al terminar
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		slog.InfoContext(ctx, "request",
+			"method", r.Method,
+			"route", r.URL.Path,
+			"status", recorder.status,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			"client_ip", extractClientIP(r),
+		)
+	})
+}
+
+// requestLogHandler agrega el request ID del contexto a cada registro de log
+type requestLogHandler struct {
+	next slog.Handler
+}
+
+// NewRequestLogHandler envuelve un slog.Handler para incluir el atributo
+// request_id cuando el log se emite con el contexto de un request
+func NewRequestLogHandler(next slog.Handler) slog.Handler {
+	return &requestLogHandler{next: next}
+}
+
+func (h *requestLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *requestLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *requestLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &requestLogHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *requestLogHandler) WithGroup(name string) slog.Handler {
+	return &requestLogHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"loan-agent/service"
+)
+
+// Códigos de los errores de la capa HTTP; los errores de validación de los
+// servicios traen su propio código (ver service.ValidationError)
+const (
+	codeMethodNotAllowed         = "METHOD_NOT_ALLOWED"
+	codeUnsupportedMediaType     = "UNSUPPORTED_MEDIA_TYPE"
+	codeInvalidRequestBody       = "INVALID_REQUEST_BODY"
+	codeRequestTooLarge          = "REQUEST_TOO_LARGE"
+	codeInvalidInput             = "INVALID_INPUT"
+	codeInvalidTimeRange         = "INVALID_TIME_RANGE"
+	codeUnauthorized             = "UNAUTHORIZED"
+	codeRateLimited              = "RATE_LIMITED"
+	codeHookRejected             = "HOOK_REJECTED"
+	codeInvalidIdempotencyKey    = "INVALID_IDEMPOTENCY_KEY"
+	codeIdempotencyKeyInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	codeIdempotencyKeyReused     = "IDEMPOTENCY_KEY_REUSED"
+	codeInvalidMaintenanceWindow = "INVALID_MAINTENANCE_WINDOW"
+	codeInvalidRateLimitConfig   = "INVALID_RATE_LIMIT_CONFIG"
+	codeMaintenance              = "MAINTENANCE"
+	codeDraining                 = "DRAINING"
+	codeServiceUnavailable       = "SERVICE_UNAVAILABLE"
+	codeInternal                 = "INTERNAL_ERROR"
+)
+
+// errorResponse es el cuerpo de todas las respuestas de error: un código
+// estable para que los clientes no comparen textos, el mensaje y detalles opcionales
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// writeError responde con el mensaje del código en el idioma de Accept-Language;
+// args son los argumentos de la plantilla en responseMessages
+func writeError(w http.ResponseWriter, r *http.Request, status int, code string, args ...any) {
+	writeErrorDetails(w, r, status, code, responseText(preferredLanguage(r), code, args...), nil)
+}
+
+func writeErrorDetails(w http.ResponseWriter, r *http.Request, status int, code, message string, details any) {
+	body, err := json.Marshal(errorResponse{Code: code, Message: message, Details: details})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "encoding error response", "error", err)
+		body = []byte(`{"code":"` + codeInternal + `","message":"internal server error"}`)
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		slog.ErrorContext(r.Context(), "writing response", "error", err)
+	}
+}
+
+// fieldErrorDetails son los detalles de un 422: todos los campos inválidos
+type fieldErrorDetails struct {
+	Fields []service.FieldError `json:"fields"`
+}
+
+// writeServiceError responde 422 con la lista de campos inválidos si la
+// validación del servicio los acumuló, y 400 con el código del error en otro
+// caso; los mensajes van en el idioma pedido (el por defecto si no se soporta)
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error, lang string) {
+	message := service.LocalizedMessage(err, lang)
+	var fields *service.ValidationErrors
+	if errors.As(err, &fields) {
+		writeErrorDetails(w, r, http.StatusUnprocessableEntity, service.ErrorCode(err), message, fieldErrorDetails{Fields: fields.LocalizedFields(lang)})
+		return
+	}
+	code := service.ErrorCode(err)
+	if code == "" {
+		code = codeInvalidInput
+	}
+	writeErrorDetails(w, r, http.StatusBadRequest, code, message, nil)
+}
+
+// writeJSON codifica el valor en un buffer primero para evitar escribir header si falla
+func writeJSON(w http.ResponseWriter, r *http.Request, value any) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(value); err != nil {
+		slog.ErrorContext(r.Context(), "encoding response", "error", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := buf.WriteTo(w); err != nil {
+		slog.ErrorContext(r.Context(), "writing response", "error", err)
+	}
+}
@@ -0,0 +1,22 @@
+package http
+
+import (
+	"net/http"
+
+	"loan-agent/service"
+)
+
+// SandboxMiddleware marca como sandbox las requests hechas con una API key de
+// un tier sandbox, o todas si global es true (SANDBOX_MODE). Sus cálculos no
+// se guardan ni disparan webhooks, y la respuesta lleva el header X-Sandbox
+// para que los integradores lo sepan.
+func SandboxMiddleware(limiter *RateLimiter, global bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !global && !limiter.SandboxKey(r.Header.Get(apiKeyHeader)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("X-Sandbox", "true")
+		next.ServeHTTP(w, r.WithContext(service.WithSandbox(r.Context())))
+	})
+}
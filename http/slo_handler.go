@@ -0,0 +1,20 @@
+package http
+
+import "net/http"
+
+type SLOHandler struct {
+	tracker *SLOTracker
+}
+
+func NewSLOHandler(tracker *SLOTracker) *SLOHandler {
+	return &SLOHandler{tracker: tracker}
+}
+
+func (h *SLOHandler) Status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, r, h.tracker.Status())
+}
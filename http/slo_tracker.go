@@ -0,0 +1,211 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"loan-agent/clock"
+	"loan-agent/service"
+)
+
+const (
+	sloWindow             = 5 * time.Minute
+	sloEvaluationInterval = time.Minute
+	sloMaxSamples         = 1000 // por endpoint dentro de la ventana
+	sloMinSamples         = 20   // mínimo para evaluar un endpoint
+)
+
+type sloSample struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+type SLOStatus struct {
+	Endpoint     string
+	Samples      int
+	P95LatencyMs float64
+	ErrorRate    float64 // porcentaje
+	Breached     []string
+}
+
+// SLOTracker mide latencia p95 y tasa de error por endpoint en una ventana
+// deslizante y dispara alertas al entrar o salir de incumplimiento
+type SLOTracker struct {
+	mu           sync.Mutex
+	latencyMs    float64
+	maxErrorRate float64
+	samples      map[string][]sloSample
+	breached     map[string]bool // endpoint+métrica en incumplimiento
+	dispatcher   service.AlertDispatcher
+	clock        clock.Clock
+	stop         chan struct{}
+}
+
+func NewSLOTracker(
+	latencyBudget time.Duration,
+	maxErrorRate float64,
+	dispatcher service.AlertDispatcher,
+) *SLOTracker {
+	t := &SLOTracker{
+		latencyMs:    float64(latencyBudget.Milliseconds()),
+		maxErrorRate: maxErrorRate,
+		samples:      make(map[string][]sloSample),
+		breached:     make(map[string]bool),
+		dispatcher:   dispatcher,
+		clock:        clock.Real{},
+		stop:         make(chan struct{}),
+	}
+	go t.evaluationLoop()
+	return t
+}
+
+func (t *SLOTracker) evaluationLoop() {
+	ticker := time.NewTicker(sloEvaluationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.evaluate()
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// SetClock reemplaza el reloj de la ventana deslizante; la latencia se sigue
+// midiendo con el reloj monotónico del sistema
+func (t *SLOTracker) SetClock(c clock.Clock) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clock = c
+}
+
+func (t *SLOTracker) Stop() {
+	close(t.stop)
+}
+
+func (t *SLOTracker) record(endpoint string, latency time.Duration, status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples := append(t.samples[endpoint], sloSample{
+		at:      t.clock.Now(),
+		latency: latency,
+		failed:  status >= http.StatusInternalServerError,
+	})
+	if len(samples) > sloMaxSamples {
+		samples = samples[len(samples)-sloMaxSamples:]
+	}
+	t.samples[endpoint] = samples
+}
+
+// Status calcula el estado actual de cada endpoint con muestras en la ventana
+func (t *SLOTracker) Status() []SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := t.clock.Now().Add(-sloWindow)
+	statuses := []SLOStatus{}
+
+	for endpoint, samples := range t.samples {
+		// Descartar muestras fuera de la ventana
+		first := sort.Search(len(samples), func(i int) bool {
+			return samples[i].at.After(cutoff)
+		})
+		samples = samples[first:]
+		t.samples[endpoint] = samples
+		if len(samples) == 0 {
+			continue
+		}
+
+		latencies := make([]float64, len(samples))
+		failed := 0
+		for i, sample := range samples {
+			latencies[i] = float64(sample.latency.Microseconds()) / 1000
+			if sample.failed {
+				failed++
+			}
+		}
+		sort.Float64s(latencies)
+
+		status := SLOStatus{
+			Endpoint:     endpoint,
+			Samples:      len(samples),
+			P95LatencyMs: latencies[(len(latencies)*95-1)/100],
+			ErrorRate:    float64(failed) / float64(len(samples)) * 100,
+		}
+		if status.P95LatencyMs > t.latencyMs {
+			status.Breached = append(status.Breached, "p95_latency")
+		}
+		if status.ErrorRate > t.maxErrorRate {
+			status.Breached = append(status.Breached, "error_rate")
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Endpoint < statuses[j].Endpoint
+	})
+	return statuses
+}
+
+// evaluate dispara una alerta cuando un endpoint empieza o deja de incumplir
+func (t *SLOTracker) evaluate() {
+	for _, status := range t.Status() {
+		if status.Samples < sloMinSamples {
+			continue
+		}
+
+		checks := []struct {
+			metric    string
+			value     float64
+			threshold float64
+		}{
+			{"p95_latency", status.P95LatencyMs, t.latencyMs},
+			{"error_rate", status.ErrorRate, t.maxErrorRate},
+		}
+		for _, check := range checks {
+			key := status.Endpoint + " " + check.metric
+			breached := check.value > check.threshold
+
+			t.mu.Lock()
+			changed := t.breached[key] != breached
+			t.breached[key] = breached
+			t.mu.Unlock()
+
+			if !changed {
+				continue
+			}
+
+			alert := service.Alert{
+				Endpoint:  status.Endpoint,
+				Metric:    check.metric,
+				Value:     check.value,
+				Threshold: check.threshold,
+				Resolved:  !breached,
+				Time:      t.clock.Now(),
+			}
+			if err := t.dispatcher.Dispatch(alert); err != nil {
+				slog.Warn("failed to dispatch SLO alert", "error", err)
+			}
+		}
+	}
+}
+
+func SLOMiddleware(
+	tracker *SLOTracker,
+	next http.Handler,
+) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		tracker.record(r.URL.Path, time.Since(start), recorder.status)
+	})
+}
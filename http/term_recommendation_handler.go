@@ -3,7 +3,7 @@ package http
 import (
 	"bytes"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -12,48 +12,57 @@ import (
 )
 
 type TermRecommendationHandler struct {
-	service *service.TermRecommendationService
+	service   *service.TermRecommendationService
+	analytics *service.AnalyticsService
 }
 
-func NewTermRecommendationHandler(service *service.TermRecommendationService) *TermRecommendationHandler {
-	return &TermRecommendationHandler{service: service}
+func NewTermRecommendationHandler(
+	service *service.TermRecommendationService,
+	analytics *service.AnalyticsService,
+) *TermRecommendationHandler {
+	return &TermRecommendationHandler{service: service, analytics: analytics}
 }
 
 func (h *TermRecommendationHandler) RecommendTerm(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed)
 		return
 	}
 
 	contentType := r.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "application/json") {
-		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		writeError(w, r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType)
 		return
 	}
 
 	var input domain.TermRecommendationInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		log.Printf("Error decoding request body: %v", err)
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "decoding request body", "error", err)
+		writeBodyError(w, r, err)
 		return
 	}
+	if input.Language == "" {
+		input.Language = preferredLanguage(r)
+	}
 
 	result, err := h.service.RecommendTerm(input)
 	if err != nil {
-		log.Printf("Error recommending term: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "recommending term", "error", err)
+		writeServiceError(w, r, err, input.Language)
 		return
 	}
 
+	h.analytics.RecordLoanAmount(input.Amount)
+
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(result); err != nil {
-		log.Printf("Error encoding response: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "encoding response", "error", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if _, err := buf.WriteTo(w); err != nil {
-		log.Printf("Error writing response: %v", err)
+		slog.ErrorContext(r.Context(), "writing response", "error", err)
 	}
 }
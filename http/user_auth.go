@@ -0,0 +1,47 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"loan-agent/auth"
+)
+
+type userIDKey struct{}
+
+// UserIDFromContext devuelve el usuario autenticado por UserAuthMiddleware;
+// vacío cuando la autenticación de usuarios está deshabilitada
+func UserIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey{}).(string)
+	return id
+}
+
+// UserAuthMiddleware exige "Authorization: Bearer <jwt>" con un token válido
+// y deja el subject del token en el contexto como usuario de la request
+func UserAuthMiddleware(
+	verifier *auth.Verifier,
+	next http.Handler,
+) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="loan-agent"`)
+			writeError(w, r, http.StatusUnauthorized, codeUnauthorized)
+			return
+		}
+
+		claims, err := verifier.Verify(r.Context(), token)
+		if err != nil {
+			slog.InfoContext(r.Context(), "rejected user token", "error", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="loan-agent", error="invalid_token"`)
+			writeError(w, r, http.StatusUnauthorized, codeUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDKey{}, claims.Subject)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -2,65 +2,283 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"loan-agent/auth"
 	httpLayer "loan-agent/http"
 	"loan-agent/repository"
+	"loan-agent/secrets"
 	"loan-agent/service"
 )
 
 func main() {
+	// Logs estructurados en JSON con el request ID de cada request
+	slog.SetDefault(slog.New(httpLayer.NewRequestLogHandler(slog.NewJSONHandler(os.Stderr, nil))))
+
+	secretsProvider, err := secrets.NewProviderFromEnv()
+	if err != nil {
+		fatal("configuring secrets provider", err)
+	}
+
+	sandbox := service.GetSandboxMode()
+	if sandbox {
+		slog.Info("sandbox mode: calculations are not persisted and webhooks are disabled")
+	}
+
 	loanRepo := repository.NewLoanRepositoryMemory()
 
 	// cache := repository.NewRedisCache("localhost:6379")
-	cache := repository.NewMockCache()
+	var cache repository.CacheRepository = repository.NewMockCache()
+
+	var encryptor *repository.FieldEncryptor
+	if keys := secretsProvider.Lookup(context.Background(), "DATA_ENCRYPTION_KEYS"); keys != "" {
+		keyProvider, err := repository.NewStaticKeyProvider(keys)
+		if err != nil {
+			fatal("invalid DATA_ENCRYPTION_KEYS", err)
+		}
+		encryptor = repository.NewFieldEncryptor(keyProvider)
+		loanRepo.SetEncryptor(encryptor)
+		cache = repository.NewEncryptedCache(cache, encryptor)
+	}
 
-	loanService := service.NewLoanService(loanRepo, cache)
+	if snapshotPath := service.GetLoanSnapshotPath(); snapshotPath != "" && !sandbox {
+		// Sin un snapshot válido el siguiente guardado lo sobrescribiría
+		if err := loanRepo.LoadSnapshot(snapshotPath); err != nil {
+			fatal("loading loan snapshot", err)
+		}
+		snapshotter := repository.NewLoanSnapshotter(loanRepo, snapshotPath, service.GetLoanSnapshotInterval())
+		snapshotter.Start()
+		defer snapshotter.Stop()
+	}
+
+	var loanStore repository.LoanRepository = loanRepo
+	if sandbox {
+		loanStore = repository.NewLoanRepositoryDiscard()
+	}
+	loanService := service.NewLoanService(loanStore, cache)
+
+	analyticsRepo := repository.NewAnalyticsRepositoryMemory(service.AnalyticsRetention)
+	analyticsService := service.NewAnalyticsService(analyticsRepo)
+	analyticsHandler := httpLayer.NewAnalyticsHandler(analyticsService)
+
+	if remoteWriteURL := service.GetPrometheusRemoteWriteURL(); remoteWriteURL != "" && !sandbox {
+		remoteWriter := service.NewPrometheusRemoteWriter(analyticsService, remoteWriteURL, service.RemoteWriteInterval)
+		remoteWriter.Start()
+		defer remoteWriter.Stop()
+	}
 
-	loanHandler := httpLayer.NewLoanHandler(loanService)
+	loanHandler := httpLayer.NewLoanHandler(loanService, analyticsService)
 
 	termRecommendationService := service.NewTermRecommendationService(loanService)
-	termRecommendationHandler := httpLayer.NewTermRecommendationHandler(termRecommendationService)
+	termRecommendationHandler := httpLayer.NewTermRecommendationHandler(termRecommendationService, analyticsService)
 
 	debtExitService := service.NewDebtExitService(loanService)
-	debtExitHandler := httpLayer.NewDebtExitHandler(debtExitService)
+	debtExitHandler := httpLayer.NewDebtExitHandler(debtExitService, analyticsService)
 
-	rateLimiter := httpLayer.NewRateLimiter(5, time.Minute)
+	consolidationService := service.NewConsolidationService(loanService, debtExitService)
+	consolidationHandler := httpLayer.NewConsolidationHandler(consolidationService)
+
+	balanceTransferService := service.NewBalanceTransferService(debtExitService)
+	balanceTransferHandler := httpLayer.NewBalanceTransferHandler(balanceTransferService)
+
+	paymentAllocationService := service.NewPaymentAllocationService()
+	paymentAllocationHandler := httpLayer.NewPaymentAllocationHandler(paymentAllocationService)
+
+	rateChangeService := service.NewRateChangeService()
+	rateChangeHandler := httpLayer.NewRateChangeHandler(rateChangeService)
+
+	rateLimiter := httpLayer.NewRateLimiter(httpLayer.RateLimiterConfig{
+		Capacity:        service.GetRateLimitCapacity(""),
+		Window:          service.GetRateLimitWindow(""),
+		CleanupInterval: service.GetRateLimitCleanupInterval(),
+		BucketTTL:       service.GetRateLimitBucketTTL(),
+	})
 	defer rateLimiter.Stop()
+	for _, group := range service.RateLimitRouteGroups {
+		rateLimiter.SetGroupLimit(group, service.GetRateLimitCapacity(group), service.GetRateLimitWindow(group))
+	}
+	// Tiers por API key (header X-API-Key); las keys son secretas, por eso la
+	// configuración se lee del proveedor de secretos
+	if tiers := secretsProvider.Lookup(context.Background(), "RATE_LIMIT_TIERS"); tiers != "" {
+		config, err := httpLayer.ParseRateLimitConfig([]byte(tiers))
+		if err != nil {
+			fatal("invalid RATE_LIMIT_TIERS", err)
+		}
+		rateLimiter.LoadTiers(config)
+	}
+
+	graphqlHandler := httpLayer.NewGraphQLHandler(
+		loanService,
+		termRecommendationService,
+		debtExitService,
+		consolidationService,
+		balanceTransferService,
+		paymentAllocationService,
+		rateChangeService,
+		analyticsService,
+		rateLimiter,
+	)
+
+	// Las reglas propias de una institución se registran aquí como
+	// hooks.Register(stage, service.HookFunc(...)) o como webhooks por etapa
+	hooks := service.NewHookRegistry()
+	for _, stage := range service.HookStages {
+		if hookURL := service.GetHookWebhookURL(stage); hookURL != "" && !sandbox {
+			hooks.Register(stage, service.NewWebhookHook(hookURL))
+		}
+	}
+
+	idempotencyStore := httpLayer.NewIdempotencyStore(cache, service.GetIdempotencyKeyTTL())
+
+	duplicateWindow := service.GetDuplicateRequestWindow()
+	duplicateDetector := httpLayer.NewDuplicateDetector(duplicateWindow)
+	if encryptor != nil {
+		duplicateDetector.SetEncryptor(encryptor)
+	}
+	defer duplicateDetector.Stop()
+
+	var alertDispatcher service.AlertDispatcher = service.LogAlertDispatcher{}
+	if webhookURL := service.GetAlertWebhookURL(); webhookURL != "" && !sandbox {
+		alertDispatcher = service.NewWebhookAlertDispatcher(webhookURL)
+	}
+	sloTracker := httpLayer.NewSLOTracker(
+		service.GetSLOLatencyBudget(),
+		service.GetSLOMaxErrorRate(),
+		alertDispatcher,
+	)
+	defer sloTracker.Stop()
+	sloHandler := httpLayer.NewSLOHandler(sloTracker)
+
+	maintenance := httpLayer.NewMaintenanceMode()
+	readiness := httpLayer.NewReadiness(service.GetDrainPeriod())
+	healthHandler := httpLayer.NewHealthHandler(maintenance, readiness)
+
+	// Con JWT_JWKS_URL o el secreto JWT_HMAC_SECRET la API exige un JWT de
+	// usuario y los cálculos guardados quedan a nombre del subject del token
+	jwtSecret := func() string {
+		return secretsProvider.Lookup(context.Background(), "JWT_HMAC_SECRET")
+	}
+	var userVerifier *auth.Verifier
+	if service.GetJWKSURL() != "" || jwtSecret() != "" {
+		userVerifier = auth.NewVerifier(auth.Config{
+			Issuer:     service.GetJWTIssuer(),
+			Audience:   service.GetJWTAudience(),
+			HMACSecret: jwtSecret,
+			JWKSURL:    service.GetJWKSURL(),
+		})
+	}
 
 	mux := http.NewServeMux()
+	// Rutas de la API v1; se montan abajo bajo /v1 y sin prefijo
+	api := http.NewServeMux()
+	handle := func(pattern string, handler http.HandlerFunc) {
+		var wrapped http.Handler = handler
+		if !hooks.Empty() {
+			wrapped = httpLayer.HooksMiddleware(hooks, wrapped)
+		}
+		if duplicateWindow > 0 {
+			wrapped = httpLayer.DuplicateRequestMiddleware(duplicateDetector, wrapped)
+		}
+		// Las API keys de un tier sandbox se resuelven por request; SANDBOX_MODE
+		// marca toda la instancia
+		wrapped = httpLayer.SandboxMiddleware(rateLimiter, sandbox, wrapped)
+		if userVerifier != nil {
+			wrapped = httpLayer.UserAuthMiddleware(userVerifier, wrapped)
+		}
+		api.Handle(
+			pattern,
+			httpLayer.SLOMiddleware(
+				sloTracker,
+				httpLayer.AnalyticsMiddleware(
+					analyticsService,
+					httpLayer.MaintenanceMiddleware(
+						maintenance,
+						// Los reintentos con Idempotency-Key no consumen rate limit
+						httpLayer.IdempotencyMiddleware(
+							idempotencyStore,
+							httpLayer.RateLimitMiddleware(rateLimiter, httpLayer.RouteGroup(pattern), wrapped),
+						),
+					),
+				),
+			),
+		)
+	}
+
+	handle("/loan/calculate", loanHandler.CalculateLoan)
+	handle("/loan/calculations", loanHandler.ListCalculations)
+	handle("/loan/tags", loanHandler.ListTags)
+	handle("/loan/recommend-term", termRecommendationHandler.RecommendTerm)
+	handle("/loan/debt-exit-plan", debtExitHandler.CalculateDebtExitPlan)
+	handle("/loan/debt-exit-target", debtExitHandler.SolveTargetPayoff)
+	handle("/loan/consolidation", consolidationHandler.CompareConsolidation)
+	handle("/loan/balance-transfer", balanceTransferHandler.AnalyzeBalanceTransfer)
+	handle("/loan/payment-allocation", paymentAllocationHandler.AllocatePayment)
+	handle("/loan/rate-change", rateChangeHandler.CompareRateChange)
+	handle("/slo/status", sloHandler.Status)
+	handle("/graphql", graphqlHandler.Serve)
+
+	// /v1 es la versión estable. Las rutas sin prefijo siguen atendiendo como
+	// alias de v1 para los clientes existentes; una /v2 con cambios
+	// incompatibles se montaría con su propio mux.
+	mux.Handle("/v1/", httpLayer.APIVersionMiddleware("v1", http.StripPrefix("/v1", api)))
+	mux.Handle("/", httpLayer.APIVersionMiddleware("v1", api))
+
+	mux.HandleFunc("/healthz", healthHandler.Healthz)
+	mux.HandleFunc("/readyz", healthHandler.Readyz)
+
+	docsHandler := httpLayer.NewDocsHandler()
+	mux.HandleFunc("/openapi.json", docsHandler.Spec)
+	mux.HandleFunc("/docs", docsHandler.Docs)
+
+	adminToken := func() string {
+		return secretsProvider.Lookup(context.Background(), "ADMIN_TOKEN")
+	}
+	secretsKind := os.Getenv("SECRETS_PROVIDER")
+	if secretsKind == "" {
+		secretsKind = "env"
+	}
+	adminHandler := httpLayer.NewAdminHandler(
+		maintenance,
+		readiness,
+		rateLimiter,
+		func(name string) string { return secretsProvider.Lookup(context.Background(), name) },
+		secretsKind,
+	)
 	mux.Handle(
-		"/loan/calculate",
-		httpLayer.RateLimitMiddleware(
-			rateLimiter,
-			http.HandlerFunc(loanHandler.CalculateLoan),
-		),
+		"/admin/maintenance",
+		httpLayer.AdminAuthMiddleware(adminToken, http.HandlerFunc(adminHandler.Maintenance)),
 	)
-
 	mux.Handle(
-		"/loan/recommend-term",
-		httpLayer.RateLimitMiddleware(
-			rateLimiter,
-			http.HandlerFunc(termRecommendationHandler.RecommendTerm),
-		),
+		"/admin/drain",
+		httpLayer.AdminAuthMiddleware(adminToken, http.HandlerFunc(adminHandler.Drain)),
 	)
-
 	mux.Handle(
-		"/loan/debt-exit-plan",
-		httpLayer.RateLimitMiddleware(
-			rateLimiter,
-			http.HandlerFunc(debtExitHandler.CalculateDebtExitPlan),
-		),
+		"/admin/rate-limits",
+		httpLayer.AdminAuthMiddleware(adminToken, http.HandlerFunc(adminHandler.RateLimits)),
+	)
+	mux.Handle(
+		"/admin/config/effective",
+		httpLayer.AdminAuthMiddleware(adminToken, http.HandlerFunc(adminHandler.EffectiveConfig)),
+	)
+	// Las métricas de uso son globales (de todos los clientes): van junto a
+	// /admin, fuera de la autenticación de usuario, que usa el mismo header
+	mux.Handle(
+		"/analytics/overview",
+		httpLayer.AdminAuthMiddleware(adminToken, http.HandlerFunc(analyticsHandler.Overview)),
+	)
+	mux.Handle(
+		"/analytics/export.csv",
+		httpLayer.AdminAuthMiddleware(adminToken, http.HandlerFunc(analyticsHandler.ExportCSV)),
 	)
 
 	server := &http.Server{
 		Addr:         ":8080",
-		Handler:      mux,
+		Handler:      httpLayer.RequestIDMiddleware(httpLayer.BodyLimitMiddleware(service.GetMaxRequestBodyBytes(), mux)),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -68,7 +286,7 @@ func main() {
 
 	serverErr := make(chan error, 1)
 	go func() {
-		log.Println("🚀 API corriendo en http://localhost:8080")
+		slog.Info("🚀 API corriendo en http://localhost:8080")
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			serverErr <- err
 		}
@@ -79,18 +297,27 @@ func main() {
 
 	select {
 	case err := <-serverErr:
-		log.Printf("Error starting server: %v", err)
+		slog.Error("server failed to start", "error", err)
 		return
 	case <-quit:
-		log.Println("Shutting down server...")
+		// Dejar que el balanceador retire la instancia antes de cerrar conexiones
+		slog.Info("draining before shutdown", "remaining", readiness.DrainRemaining().String())
+		readiness.WaitForDrain()
+		slog.Info("shutting down server")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		log.Printf("Error during server shutdown: %v", err)
+		slog.Error("server shutdown failed", "error", err)
 	}
 
-	log.Println("Server exited")
+	slog.Info("server exited")
+}
+
+// fatal registra el error de arranque y termina el proceso
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"time"
+
+	"loan-agent/domain"
+)
+
+// AnalyticsRepository guarda métricas de uso pre-agregadas por hora
+type AnalyticsRepository interface {
+	RecordRequest(at time.Time, endpoint string, statusCode int) error
+	RecordLoanAmount(at time.Time, amount float64) error
+	RecordStrategy(at time.Time, strategy string) error
+	// Buckets devuelve los buckets horarios cuyo inicio está en [from, to)
+	Buckets(from, to time.Time) ([]domain.AnalyticsBucket, error)
+}
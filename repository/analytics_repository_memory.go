@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"maps"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"loan-agent/domain"
+)
+
+// AnalyticsRepositoryMemory is an in-memory implementation of AnalyticsRepository.
+// Events are aggregated into hourly buckets on write and buckets older than the
+// retention period are discarded.
+type AnalyticsRepositoryMemory struct {
+	mu        sync.Mutex
+	retention time.Duration
+	buckets   map[int64]*domain.AnalyticsBucket
+}
+
+// NewAnalyticsRepositoryMemory creates a new in-memory analytics repository.
+func NewAnalyticsRepositoryMemory(retention time.Duration) *AnalyticsRepositoryMemory {
+	return &AnalyticsRepositoryMemory{
+		retention: retention,
+		buckets:   make(map[int64]*domain.AnalyticsBucket),
+	}
+}
+
+// bucket returns the hourly bucket for the given time, creating it if needed.
+// Callers must hold the lock.
+func (r *AnalyticsRepositoryMemory) bucket(at time.Time) *domain.AnalyticsBucket {
+	start := at.UTC().Truncate(time.Hour)
+	key := start.Unix()
+
+	b, exists := r.buckets[key]
+	if !exists {
+		b = &domain.AnalyticsBucket{
+			Start:       start,
+			Requests:    make(map[string]int),
+			StrategyMix: make(map[string]int),
+		}
+		r.buckets[key] = b
+		r.prune(start)
+	}
+	return b
+}
+
+// prune removes buckets outside the retention period. Callers must hold the lock.
+func (r *AnalyticsRepositoryMemory) prune(now time.Time) {
+	cutoff := now.Add(-r.retention).Unix()
+	for key := range r.buckets {
+		if key < cutoff {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+// RecordRequest counts a request to the given endpoint.
+func (r *AnalyticsRepositoryMemory) RecordRequest(at time.Time, endpoint string, statusCode int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := r.bucket(at)
+	b.Requests[endpoint]++
+	b.TotalRequests++
+	if statusCode == http.StatusTooManyRequests {
+		b.RateLimitRejections++
+	} else if statusCode >= http.StatusBadRequest {
+		b.Errors++
+	}
+	return nil
+}
+
+// RecordLoanAmount adds a requested loan amount to the bucket average.
+func (r *AnalyticsRepositoryMemory) RecordLoanAmount(at time.Time, amount float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := r.bucket(at)
+	b.LoanCount++
+	b.LoanAmountTotal += amount
+	b.AverageLoanAmount = b.LoanAmountTotal / float64(b.LoanCount)
+	return nil
+}
+
+// RecordStrategy counts a debt exit strategy request.
+func (r *AnalyticsRepositoryMemory) RecordStrategy(at time.Time, strategy string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bucket(at).StrategyMix[strategy]++
+	return nil
+}
+
+// Buckets returns copies of the hourly buckets starting in [from, to), sorted by start.
+func (r *AnalyticsRepositoryMemory) Buckets(from, to time.Time) ([]domain.AnalyticsBucket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buckets := []domain.AnalyticsBucket{}
+	for _, b := range r.buckets {
+		if b.Start.Before(from) || !b.Start.Before(to) {
+			continue
+		}
+		copied := *b
+		copied.Requests = maps.Clone(b.Requests)
+		copied.StrategyMix = maps.Clone(b.StrategyMix)
+		buckets = append(buckets, copied)
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets, nil
+}
@@ -1,6 +1,11 @@
 package repository
 
+import "time"
+
 type CacheRepository interface {
 	Get(key string) (string, bool)
 	Set(key string, value string) error
+	// SetWithTTL stores a value that expires after ttl; the key is removed
+	// from the backing store, not just hidden from Get.
+	SetWithTTL(key string, value string, ttl time.Duration) error
 }
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"log/slog"
+	"time"
+)
+
+// EncryptedCache wraps a CacheRepository and encrypts every stored value.
+type EncryptedCache struct {
+	next      CacheRepository
+	encryptor *FieldEncryptor
+}
+
+// NewEncryptedCache creates a cache that encrypts values before storing them in next.
+func NewEncryptedCache(next CacheRepository, encryptor *FieldEncryptor) *EncryptedCache {
+	return &EncryptedCache{next: next, encryptor: encryptor}
+}
+
+func (c *EncryptedCache) Get(key string) (string, bool) {
+	value, ok := c.next.Get(key)
+	if !ok {
+		return "", false
+	}
+
+	plaintext, err := c.encryptor.Decrypt(value)
+	if err != nil {
+		slog.Warn("failed to decrypt cached value", "error", err)
+		return "", false
+	}
+	return plaintext, true
+}
+
+func (c *EncryptedCache) Set(key string, value string) error {
+	encrypted, err := c.encryptor.Encrypt(value)
+	if err != nil {
+		return err
+	}
+	return c.next.Set(key, encrypted)
+}
+
+func (c *EncryptedCache) SetWithTTL(key string, value string, ttl time.Duration) error {
+	encrypted, err := c.encryptor.Encrypt(value)
+	if err != nil {
+		return err
+	}
+	return c.next.SetWithTTL(key, encrypted, ttl)
+}
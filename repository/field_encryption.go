@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const encryptedFieldPrefix = "enc:v1:"
+
+// KeyProvider supplies AES-256 keys by id. Implementations can wrap a KMS;
+// the primary key encrypts new data while older keys remain available for
+// decryption during rotation.
+type KeyProvider interface {
+	PrimaryKey() (id string, key []byte, err error)
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeyProvider holds keys loaded from configuration.
+type StaticKeyProvider struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyProvider parses "id:base64key[,id:base64key...]". The first key
+// is the primary; the rest are kept to decrypt data written before a rotation.
+func NewStaticKeyProvider(spec string) (*StaticKeyProvider, error) {
+	provider := &StaticKeyProvider{keys: make(map[string][]byte)}
+
+	for _, entry := range strings.Split(spec, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, errors.New("invalid key entry, expected id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes for AES-256", id)
+		}
+		if _, exists := provider.keys[id]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", id)
+		}
+		if provider.primary == "" {
+			provider.primary = id
+		}
+		provider.keys[id] = key
+	}
+
+	return provider, nil
+}
+
+func (p *StaticKeyProvider) PrimaryKey() (string, []byte, error) {
+	return p.primary, p.keys[p.primary], nil
+}
+
+func (p *StaticKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %q", id)
+	}
+	return key, nil
+}
+
+// FieldEncryptor encrypts individual string fields with AES-GCM. Encrypted
+// values look like "enc:v1:<key id>:<base64 nonce+ciphertext>" so they can be
+// decrypted with the right key after a rotation.
+type FieldEncryptor struct {
+	keys KeyProvider
+}
+
+// NewFieldEncryptor creates an encryptor backed by the given key provider.
+func NewFieldEncryptor(keys KeyProvider) *FieldEncryptor {
+	return &FieldEncryptor{keys: keys}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts plaintext with the primary key.
+func (e *FieldEncryptor) Encrypt(plaintext string) (string, error) {
+	id, key, err := e.keys.PrimaryKey()
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(id))
+
+	return encryptedFieldPrefix + id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt. Values without the encrypted
+// prefix are returned unchanged so data written before encryption was enabled
+// stays readable.
+func (e *FieldEncryptor) Decrypt(value string) (string, error) {
+	rest, ok := strings.CutPrefix(value, encryptedFieldPrefix)
+	if !ok {
+		return value, nil
+	}
+
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", errors.New("malformed encrypted field")
+	}
+	key, err := e.keys.Key(id)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted field")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(id))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Reencrypt decrypts value with the key that wrote it and encrypts it again
+// with the primary key. Plaintext values are encrypted for the first time.
+func (e *FieldEncryptor) Reencrypt(value string) (string, error) {
+	plaintext, err := e.Decrypt(value)
+	if err != nil {
+		return "", err
+	}
+	return e.Encrypt(plaintext)
+}
@@ -0,0 +1,39 @@
+package repository
+
+import "loan-agent/domain"
+
+// transformLoanRecord returns a copy of record with fn applied to every field
+// that holds personal data: the user id (usually the email in the JWT
+// subject), the tags and the insurance names. The caller's slices are never
+// modified.
+func transformLoanRecord(record domain.LoanRecord, fn func(string) (string, error)) (domain.LoanRecord, error) {
+	var err error
+	if record.UserID, err = fn(record.UserID); err != nil {
+		return domain.LoanRecord{}, err
+	}
+	if record.Input.UserID, err = fn(record.Input.UserID); err != nil {
+		return domain.LoanRecord{}, err
+	}
+
+	if record.Input.Tags != nil {
+		tags := make([]string, len(record.Input.Tags))
+		for i, tag := range record.Input.Tags {
+			if tags[i], err = fn(tag); err != nil {
+				return domain.LoanRecord{}, err
+			}
+		}
+		record.Input.Tags = tags
+	}
+
+	if record.Input.Insurances != nil {
+		insurances := make([]domain.Insurance, len(record.Input.Insurances))
+		for i, insurance := range record.Input.Insurances {
+			if insurance.Name, err = fn(insurance.Name); err != nil {
+				return domain.LoanRecord{}, err
+			}
+			insurances[i] = insurance
+		}
+		record.Input.Insurances = insurances
+	}
+	return record, nil
+}
@@ -3,5 +3,12 @@ package repository
 import "loan-agent/domain"
 
 type LoanRepository interface {
+	// Save guarda el cálculo a nombre de input.UserID (vacío para anónimos)
 	Save(input domain.LoanInput, result domain.LoanResult) error
+	// List devuelve los cálculos guardados del usuario, filtrados por tag si no está vacío
+	List(userID, tag string) ([]domain.LoanRecord, error)
+	// Tags devuelve los tags en uso por el usuario con la cantidad de cálculos de cada uno
+	Tags(userID string) ([]domain.TagCount, error)
+	// InterestRates devuelve la tasa de cada cálculo guardado, sin ningún otro dato
+	InterestRates() ([]float64, error)
 }
@@ -0,0 +1,35 @@
+package repository
+
+import "loan-agent/domain"
+
+// LoanRepositoryDiscard is a LoanRepository that stores nothing. It backs
+// sandbox deployments, where calculations run normally but never persist.
+type LoanRepositoryDiscard struct{}
+
+// NewLoanRepositoryDiscard creates a repository that drops every record.
+func NewLoanRepositoryDiscard() *LoanRepositoryDiscard {
+	return &LoanRepositoryDiscard{}
+}
+
+// Save discards the calculation.
+func (r *LoanRepositoryDiscard) Save(
+	input domain.LoanInput,
+	result domain.LoanResult,
+) error {
+	return nil
+}
+
+// List always returns an empty list.
+func (r *LoanRepositoryDiscard) List(userID, tag string) ([]domain.LoanRecord, error) {
+	return []domain.LoanRecord{}, nil
+}
+
+// Tags always returns an empty list.
+func (r *LoanRepositoryDiscard) Tags(userID string) ([]domain.TagCount, error) {
+	return []domain.TagCount{}, nil
+}
+
+// InterestRates always returns an empty list.
+func (r *LoanRepositoryDiscard) InterestRates() ([]float64, error) {
+	return []float64{}, nil
+}
@@ -1,24 +1,141 @@
 package repository
 
-import "loan-agent/domain"
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	"loan-agent/clock"
+	"loan-agent/domain"
+)
 
 // LoanRepositoryMemory is an in-memory implementation of LoanRepository.
 type LoanRepositoryMemory struct {
-	data []domain.LoanResult
+	mu        sync.RWMutex
+	data      []domain.LoanRecord
+	encryptor *FieldEncryptor
+	clock     clock.Clock
 }
 
 // NewLoanRepositoryMemory creates a new in-memory loan repository.
 func NewLoanRepositoryMemory() *LoanRepositoryMemory {
 	return &LoanRepositoryMemory{
-		data: []domain.LoanResult{},
+		data:  []domain.LoanRecord{},
+		clock: clock.Real{},
 	}
 }
 
+// SetClock replaces the clock used to timestamp records and snapshots.
+func (r *LoanRepositoryMemory) SetClock(c clock.Clock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clock = c
+}
+
+// SetEncryptor enables encryption of personal data (user id, tags and
+// insurance names) in memory and in snapshots. Records saved before the
+// encryptor was set stay readable.
+func (r *LoanRepositoryMemory) SetEncryptor(encryptor *FieldEncryptor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.encryptor = encryptor
+}
+
 // Save stores the loan result in memory.
 func (r *LoanRepositoryMemory) Save(
 	input domain.LoanInput,
 	result domain.LoanResult,
 ) error {
-	r.data = append(r.data, result)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// La tabla de amortización se puede recalcular; no se guarda para ahorrar memoria
+	result.Schedule = nil
+	record := domain.LoanRecord{
+		UserID:    input.UserID,
+		Input:     input,
+		Result:    result,
+		CreatedAt: r.clock.Now(),
+	}
+	if r.encryptor != nil {
+		encrypted, err := transformLoanRecord(record, r.encryptor.Encrypt)
+		if err != nil {
+			return err
+		}
+		record = encrypted
+	}
+	r.data = append(r.data, record)
 	return nil
 }
+
+// decrypt returns the stored record with its personal data in plaintext.
+// Callers must hold r.mu.
+func (r *LoanRepositoryMemory) decrypt(record domain.LoanRecord) (domain.LoanRecord, error) {
+	if r.encryptor == nil {
+		return record, nil
+	}
+	return transformLoanRecord(record, r.encryptor.Decrypt)
+}
+
+// List returns the records stored for userID, filtered by tag when tag is not empty.
+func (r *LoanRepositoryMemory) List(userID, tag string) ([]domain.LoanRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := []domain.LoanRecord{}
+	for _, stored := range r.data {
+		record, err := r.decrypt(stored)
+		if err != nil {
+			return nil, err
+		}
+		if record.UserID != userID {
+			continue
+		}
+		if tag == "" || slices.Contains(record.Input.Tags, tag) {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// Tags returns every tag userID has in use along with how many records carry it.
+func (r *LoanRepositoryMemory) Tags(userID string) ([]domain.TagCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, stored := range r.data {
+		record, err := r.decrypt(stored)
+		if err != nil {
+			return nil, err
+		}
+		if record.UserID != userID {
+			continue
+		}
+		for _, tag := range record.Input.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]domain.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, domain.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags, nil
+}
+
+// InterestRates returns the rate of every stored record and nothing else, so
+// aggregate statistics never touch amounts or tags.
+func (r *LoanRepositoryMemory) InterestRates() ([]float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rates := make([]float64, len(r.data))
+	for i, record := range r.data {
+		rates[i] = record.Input.InterestRate
+	}
+	return rates, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"loan-agent/domain"
+)
+
+const loanSnapshotVersion = 1
+
+type loanSnapshot struct {
+	Version int
+	SavedAt time.Time
+	Records []domain.LoanRecord
+}
+
+// SaveSnapshot writes every stored record to path as JSON. The file is written
+// to a temporary file first and renamed so a crash never leaves a partial snapshot.
+func (r *LoanRepositoryMemory) SaveSnapshot(path string) error {
+	r.mu.RLock()
+	snapshot := loanSnapshot{
+		Version: loanSnapshotVersion,
+		SavedAt: r.clock.Now(),
+		Records: append([]domain.LoanRecord(nil), r.data...),
+	}
+	encryptor := r.encryptor
+	r.mu.RUnlock()
+
+	// Los registros ya están cifrados en memoria; volver a cifrarlos con la
+	// llave primaria rota los datos escritos con llaves anteriores
+	if encryptor != nil {
+		for i, record := range snapshot.Records {
+			rotated, err := transformLoanRecord(record, encryptor.Reencrypt)
+			if err != nil {
+				return err
+			}
+			snapshot.Records[i] = rotated
+		}
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot replaces the stored records with the ones in the snapshot at
+// path. A missing file is not an error.
+func (r *LoanRepositoryMemory) LoadSnapshot(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snapshot loanSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+	if snapshot.Version != loanSnapshotVersion {
+		return errors.New("unsupported snapshot version")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Los registros se mantienen cifrados en memoria; los snapshots escritos
+	// antes de activar el cifrado se cifran aquí
+	if r.encryptor != nil {
+		for i, record := range snapshot.Records {
+			encrypted, err := transformLoanRecord(record, r.encryptor.Reencrypt)
+			if err != nil {
+				return err
+			}
+			snapshot.Records[i] = encrypted
+		}
+	}
+
+	r.data = snapshot.Records
+	return nil
+}
+
+// LoanSnapshotter periodically snapshots a memory repository to disk.
+type LoanSnapshotter struct {
+	repo     *LoanRepositoryMemory
+	path     string
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewLoanSnapshotter creates a snapshotter; call Start to begin snapshotting.
+func NewLoanSnapshotter(repo *LoanRepositoryMemory, path string, interval time.Duration) *LoanSnapshotter {
+	return &LoanSnapshotter{
+		repo:     repo,
+		path:     path,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the snapshot loop in the background.
+func (s *LoanSnapshotter) Start() {
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.repo.SaveSnapshot(s.path); err != nil {
+					slog.Warn("failed to snapshot loan repository", "error", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the snapshot loop and writes a final snapshot.
+func (s *LoanSnapshotter) Stop() {
+	close(s.stop)
+	<-s.done
+
+	if err := s.repo.SaveSnapshot(s.path); err != nil {
+		slog.Warn("failed to write final loan snapshot", "error", err)
+	}
+}
@@ -1,21 +1,78 @@
 package repository
 
+import (
+	"sync"
+	"time"
+)
+
+// mockCachePruneInterval bounds how often Set sweeps expired entries
+const mockCachePruneInterval = time.Minute
+
 type MockCache struct {
-	Data map[string]string
+	mu        sync.RWMutex
+	Data      map[string]string
+	expires   map[string]time.Time
+	lastPrune time.Time
 }
 
 func NewMockCache() *MockCache {
 	return &MockCache{
-		Data: make(map[string]string),
+		Data:    make(map[string]string),
+		expires: make(map[string]time.Time),
 	}
 }
 
 func (m *MockCache) Get(key string) (string, bool) {
+	m.mu.RLock()
 	val, ok := m.Data[key]
-	return val, ok
+	expiresAt, expiring := m.expires[key]
+	m.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	if expiring && !time.Now().Before(expiresAt) {
+		m.mu.Lock()
+		m.deleteIfExpired(key, time.Now())
+		m.mu.Unlock()
+		return "", false
+	}
+	return val, true
 }
 
 func (m *MockCache) Set(key string, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Data[key] = value
+	delete(m.expires, key)
+	m.pruneExpired()
+	return nil
+}
+
+func (m *MockCache) SetWithTTL(key string, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.Data[key] = value
+	m.expires[key] = time.Now().Add(ttl)
+	m.pruneExpired()
 	return nil
 }
+
+// pruneExpired removes the expired entries that were never read again; it
+// runs at most once per mockCachePruneInterval. The caller holds m.mu.
+func (m *MockCache) pruneExpired() {
+	now := time.Now()
+	if now.Sub(m.lastPrune) < mockCachePruneInterval {
+		return
+	}
+	m.lastPrune = now
+	for key := range m.expires {
+		m.deleteIfExpired(key, now)
+	}
+}
+
+func (m *MockCache) deleteIfExpired(key string, now time.Time) {
+	if expiresAt, ok := m.expires[key]; ok && !now.Before(expiresAt) {
+		delete(m.Data, key)
+		delete(m.expires, key)
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -32,3 +33,8 @@ func (r *RedisCache) Get(key string) (string, bool) {
 func (r *RedisCache) Set(key string, value string) error {
 	return r.client.Set(r.ctx, key, value, 0).Err()
 }
+
+// SetWithTTL stores the value with SET ... EX so Redis evicts it on expiry
+func (r *RedisCache) SetWithTTL(key string, value string, ttl time.Duration) error {
+	return r.client.Set(r.ctx, key, value, ttl).Err()
+}
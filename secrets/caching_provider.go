@@ -0,0 +1,98 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultCacheTTL = 5 * time.Minute
+
+type cacheEntry struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// CachingProvider cachea los secretos por un TTL y avisa a los hooks
+// registrados cuando un secreto cambia al refrescarse (rotación)
+type CachingProvider struct {
+	next Provider
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	hooks   map[string][]func(value string)
+}
+
+func NewCachingProvider(next Provider, ttl time.Duration) *CachingProvider {
+	return &CachingProvider{
+		next:    next,
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+		hooks:   make(map[string][]func(value string)),
+	}
+}
+
+func (p *CachingProvider) Get(ctx context.Context, name string) (string, error) {
+	p.mu.Lock()
+	entry, cached := p.entries[name]
+	p.mu.Unlock()
+
+	if cached && time.Since(entry.fetchedAt) < p.ttl {
+		return entry.value, nil
+	}
+
+	value, err := p.next.Get(ctx, name)
+	if err != nil {
+		// Si el backend falla, seguir usando el último valor conocido
+		if cached && !errors.Is(err, ErrNotFound) {
+			slog.Warn("failed to refresh secret, using cached value", "secret", name, "error", err)
+			return entry.value, nil
+		}
+		return "", err
+	}
+
+	p.mu.Lock()
+	p.entries[name] = cacheEntry{value: value, fetchedAt: time.Now()}
+	hooks := p.hooks[name]
+	p.mu.Unlock()
+
+	if cached && entry.value != value {
+		slog.Info("secret rotated", "secret", name)
+		for _, hook := range hooks {
+			hook(value)
+		}
+	}
+	return value, nil
+}
+
+// OnRotate registra una función que se llama con el nuevo valor cuando el secreto cambia
+func (p *CachingProvider) OnRotate(name string, hook func(value string)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hooks[name] = append(p.hooks[name], hook)
+}
+
+// Lookup devuelve el secreto o "" si no existe, para secretos opcionales
+func (p *CachingProvider) Lookup(ctx context.Context, name string) string {
+	value, err := p.Get(ctx, name)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			slog.Warn("failed to read secret", "secret", name, "error", err)
+		}
+		return ""
+	}
+	return value
+}
+
+func cacheTTLFromEnv() time.Duration {
+	if envTTL := os.Getenv("SECRETS_CACHE_TTL"); envTTL != "" {
+		if ttl, err := time.ParseDuration(envTTL); err == nil && ttl >= 0 {
+			return ttl
+		}
+	}
+	return defaultCacheTTL
+}
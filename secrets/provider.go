@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound indica que el proveedor no tiene el secreto solicitado
+var ErrNotFound = errors.New("secret not found")
+
+// Provider obtiene credenciales por nombre (ej. "ADMIN_TOKEN")
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// EnvProvider lee los secretos de variables de entorno
+type EnvProvider struct{}
+
+func (EnvProvider) Get(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// FileProvider lee cada secreto de un archivo con su nombre dentro de un
+// directorio, como los secrets montados por Docker o Kubernetes
+type FileProvider struct {
+	dir string
+}
+
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+func (p *FileProvider) Get(_ context.Context, name string) (string, error) {
+	if strings.ContainsAny(name, `/\`) || name == ".." {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// ChainProvider consulta los proveedores en orden y devuelve el primer secreto encontrado
+type ChainProvider struct {
+	providers []Provider
+}
+
+func NewChainProvider(providers ...Provider) *ChainProvider {
+	return &ChainProvider{providers: providers}
+}
+
+func (p *ChainProvider) Get(ctx context.Context, name string) (string, error) {
+	for _, provider := range p.providers {
+		value, err := provider.Get(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		return value, err
+	}
+	return "", ErrNotFound
+}
+
+// NewProviderFromEnv arma el proveedor según SECRETS_PROVIDER ("env", "file",
+// "vault"). Los proveedores file y vault recurren a variables de entorno para
+// los secretos que no tengan, y el resultado se cachea por SECRETS_CACHE_TTL.
+func NewProviderFromEnv() (*CachingProvider, error) {
+	var primary Provider
+	switch kind := os.Getenv("SECRETS_PROVIDER"); kind {
+	case "", "env":
+		primary = EnvProvider{}
+	case "file":
+		dir := os.Getenv("SECRETS_DIR")
+		if dir == "" {
+			dir = "/run/secrets"
+		}
+		primary = NewChainProvider(NewFileProvider(dir), EnvProvider{})
+	case "vault":
+		vault, err := NewVaultProviderFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		primary = NewChainProvider(vault, EnvProvider{})
+	default:
+		return nil, fmt.Errorf("unsupported SECRETS_PROVIDER %q", kind)
+	}
+
+	return NewCachingProvider(primary, cacheTTLFromEnv()), nil
+}
@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultProvider lee secretos de un engine KV v2 de HashiCorp Vault. Todos los
+// secretos viven en una misma ruta y cada nombre es una clave dentro de ella.
+type VaultProvider struct {
+	addr   string
+	token  string
+	mount  string
+	path   string
+	client *http.Client
+}
+
+func NewVaultProvider(addr, token, mount, path string) *VaultProvider {
+	return &VaultProvider{
+		addr:   strings.TrimSuffix(addr, "/"),
+		token:  token,
+		mount:  mount,
+		path:   strings.Trim(path, "/"),
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// NewVaultProviderFromEnv usa VAULT_ADDR, VAULT_TOKEN, VAULT_KV_MOUNT (por
+// defecto "secret") y VAULT_SECRET_PATH
+func NewVaultProviderFromEnv() (*VaultProvider, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	path := os.Getenv("VAULT_SECRET_PATH")
+	if addr == "" || token == "" || path == "" {
+		return nil, errors.New("VAULT_ADDR, VAULT_TOKEN and VAULT_SECRET_PATH are required")
+	}
+
+	mount := os.Getenv("VAULT_KV_MOUNT")
+	if mount == "" {
+		mount = "secret"
+	}
+	return NewVaultProvider(addr, token, mount, path), nil
+}
+
+func (p *VaultProvider) Get(ctx context.Context, name string) (string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.addr, p.mount, p.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responded %s", resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	value, ok := body.Data.Data[name].(string)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import "loan-agent/domain"
+
+// calculateAffordability combina ingresos y obligaciones de todos los deudores.
+// El ingreso de cada deudor se pondera según su rol (ver GetBorrowerIncomeWeight)
+// y la capacidad es el ingreso ponderado por el DTI máximo menos las obligaciones.
+func calculateAffordability(borrowers []domain.Borrower) (domain.Affordability, error) {
+	if len(borrowers) > MaxBorrowersPerRequest {
+		return domain.Affordability{}, validationError("TOO_MANY_BORROWERS", MaxBorrowersPerRequest)
+	}
+
+	primaries := 0
+	totalIncome := 0.0
+	qualifyingIncome := 0.0
+	obligations := 0.0
+
+	for _, borrower := range borrowers {
+		weight, ok := GetBorrowerIncomeWeight(borrower.Role)
+		if !ok {
+			return domain.Affordability{}, validationError("INVALID_BORROWER_ROLE")
+		}
+		if borrower.Role == "primary" {
+			primaries++
+		}
+
+		for _, income := range borrower.Incomes {
+			if income.MonthlyAmount < 0 {
+				return domain.Affordability{}, validationError("INVALID_MONTHLY_INCOME")
+			}
+			totalIncome += income.MonthlyAmount
+			qualifyingIncome += income.MonthlyAmount * weight
+		}
+		for _, obligation := range borrower.Obligations {
+			if obligation.MonthlyPayment < 0 {
+				return domain.Affordability{}, validationError("INVALID_OBLIGATION_PAYMENT")
+			}
+			obligations += obligation.MonthlyPayment
+		}
+	}
+
+	if primaries != 1 {
+		return domain.Affordability{}, validationError("PRIMARY_BORROWER_REQUIRED")
+	}
+
+	maxDTI := GetMaxDebtToIncome()
+	capacity := qualifyingIncome*maxDTI/100 - obligations
+	if capacity <= 0 {
+		return domain.Affordability{}, validationError("NO_PAYMENT_CAPACITY")
+	}
+
+	return domain.Affordability{
+		TotalIncome:         roundTo2Decimals(totalIncome),
+		QualifyingIncome:    roundTo2Decimals(qualifyingIncome),
+		ExistingObligations: roundTo2Decimals(obligations),
+		MaxDebtToIncome:     maxDTI,
+		MonthlyCapacity:     roundTo2Decimals(capacity),
+	}, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type Alert struct {
+	Endpoint  string
+	Metric    string // "p95_latency", "error_rate"
+	Value     float64
+	Threshold float64
+	Resolved  bool
+	Time      time.Time
+}
+
+// Message devuelve una descripción legible de la alerta
+func (a Alert) Message() string {
+	status := "FIRING"
+	if a.Resolved {
+		status = "RESOLVED"
+	}
+	switch a.Metric {
+	case "p95_latency":
+		return fmt.Sprintf("[%s] %s p95 latency %.0fms (budget %.0fms)", status, a.Endpoint, a.Value, a.Threshold)
+	case "error_rate":
+		return fmt.Sprintf("[%s] %s error rate %.2f%% (budget %.2f%%)", status, a.Endpoint, a.Value, a.Threshold)
+	}
+	return fmt.Sprintf("[%s] %s %s %.2f (threshold %.2f)", status, a.Endpoint, a.Metric, a.Value, a.Threshold)
+}
+
+type AlertDispatcher interface {
+	Dispatch(alert Alert) error
+}
+
+// LogAlertDispatcher escribe las alertas en el log
+type LogAlertDispatcher struct{}
+
+func (LogAlertDispatcher) Dispatch(alert Alert) error {
+	slog.Warn("SLO alert", "endpoint", alert.Endpoint, "metric", alert.Metric, "resolved", alert.Resolved, "message", alert.Message())
+	return nil
+}
+
+// WebhookAlertDispatcher envía las alertas como JSON a un webhook. El campo
+// "text" hace el payload compatible con los incoming webhooks de Slack.
+type WebhookAlertDispatcher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookAlertDispatcher(url string) *WebhookAlertDispatcher {
+	return &WebhookAlertDispatcher{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (d *WebhookAlertDispatcher) Dispatch(alert Alert) error {
+	payload := struct {
+		Text  string `json:"text"`
+		Alert Alert  `json:"alert"`
+	}{
+		Text:  alert.Message(),
+		Alert: alert,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := d.client.Post(d.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook respondió %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"encoding/csv"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"loan-agent/clock"
+	"loan-agent/domain"
+	"loan-agent/repository"
+)
+
+type AnalyticsService struct {
+	repo  repository.AnalyticsRepository
+	clock clock.Clock
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository) *AnalyticsService {
+	return &AnalyticsService{repo: repo, clock: clock.Real{}}
+}
+
+// SetClock reemplaza el reloj con el que se fechan las métricas
+func (s *AnalyticsService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// RecordRequest registra una request atendida (no crítico si falla)
+func (s *AnalyticsService) RecordRequest(endpoint string, statusCode int) {
+	if err := s.repo.RecordRequest(s.clock.Now(), endpoint, statusCode); err != nil {
+		slog.Warn("failed to record request analytics", "error", err)
+	}
+}
+
+// RecordLoanAmount registra el monto solicitado en un cálculo (no crítico si falla)
+func (s *AnalyticsService) RecordLoanAmount(amount float64) {
+	if err := s.repo.RecordLoanAmount(s.clock.Now(), amount); err != nil {
+		slog.Warn("failed to record loan analytics", "error", err)
+	}
+}
+
+// RecordStrategy registra la estrategia usada en un plan de deudas (no crítico si falla)
+func (s *AnalyticsService) RecordStrategy(strategy string) {
+	if err := s.repo.RecordStrategy(s.clock.Now(), strategy); err != nil {
+		slog.Warn("failed to record strategy analytics", "error", err)
+	}
+}
+
+// Overview agrupa los buckets horarios en series por hora o por día
+func (s *AnalyticsService) Overview(
+	from, to time.Time,
+	interval string,
+) (domain.AnalyticsOverview, error) {
+
+	var step time.Duration
+	switch interval {
+	case "hour":
+		step = time.Hour
+	case "day":
+		step = 24 * time.Hour
+	default:
+		return domain.AnalyticsOverview{}, validationError("INVALID_INTERVAL")
+	}
+	if !from.Before(to) {
+		return domain.AnalyticsOverview{}, validationError("INVALID_DATE_RANGE")
+	}
+	if to.Sub(from) > MaxAnalyticsRange {
+		return domain.AnalyticsOverview{}, validationError("DATE_RANGE_TOO_LONG", int(MaxAnalyticsRange.Hours()/24))
+	}
+
+	from = from.UTC().Truncate(step)
+	hourly, err := s.repo.Buckets(from, to)
+	if err != nil {
+		return domain.AnalyticsOverview{}, err
+	}
+
+	series := []domain.AnalyticsBucket{}
+	for _, b := range hourly {
+		start := b.Start.Truncate(step)
+		if len(series) == 0 || !series[len(series)-1].Start.Equal(start) {
+			series = append(series, newAnalyticsBucket(start))
+		}
+		mergeAnalyticsBucket(&series[len(series)-1], b)
+	}
+
+	totals := newAnalyticsBucket(from)
+	for _, b := range series {
+		mergeAnalyticsBucket(&totals, b)
+	}
+
+	return domain.AnalyticsOverview{
+		From:     from,
+		To:       to,
+		Interval: interval,
+		Totals:   totals,
+		Series:   series,
+	}, nil
+}
+
+func newAnalyticsBucket(start time.Time) domain.AnalyticsBucket {
+	return domain.AnalyticsBucket{
+		Start:       start,
+		Requests:    make(map[string]int),
+		StrategyMix: make(map[string]int),
+	}
+}
+
+// mergeAnalyticsBucket suma src en dst y recalcula el promedio de monto
+func mergeAnalyticsBucket(dst *domain.AnalyticsBucket, src domain.AnalyticsBucket) {
+	for endpoint, count := range src.Requests {
+		dst.Requests[endpoint] += count
+	}
+	for strategy, count := range src.StrategyMix {
+		dst.StrategyMix[strategy] += count
+	}
+	dst.TotalRequests += src.TotalRequests
+	dst.Errors += src.Errors
+	dst.RateLimitRejections += src.RateLimitRejections
+	dst.LoanCount += src.LoanCount
+	dst.LoanAmountTotal = roundTo2Decimals(dst.LoanAmountTotal + src.LoanAmountTotal)
+	if dst.LoanCount > 0 {
+		dst.AverageLoanAmount = roundTo2Decimals(dst.LoanAmountTotal / float64(dst.LoanCount))
+	}
+}
+
+// ExportCSV escribe la serie del overview en CSV, con una columna por endpoint
+// y por estrategia presentes en el rango
+func (s *AnalyticsService) ExportCSV(overview domain.AnalyticsOverview, w io.Writer) error {
+	endpoints := sortedKeys(overview.Totals.Requests)
+	strategies := sortedKeys(overview.Totals.StrategyMix)
+
+	header := []string{
+		"start", "total_requests", "errors", "rate_limit_rejections",
+		"loan_count", "average_loan_amount",
+	}
+	for _, endpoint := range endpoints {
+		header = append(header, "requests:"+endpoint)
+	}
+	for _, strategy := range strategies {
+		header = append(header, "strategy:"+strategy)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, b := range overview.Series {
+		row := []string{
+			b.Start.Format(time.RFC3339),
+			strconv.Itoa(b.TotalRequests),
+			strconv.Itoa(b.Errors),
+			strconv.Itoa(b.RateLimitRejections),
+			strconv.Itoa(b.LoanCount),
+			strconv.FormatFloat(b.AverageLoanAmount, 'f', 2, 64),
+		}
+		for _, endpoint := range endpoints {
+			row = append(row, strconv.Itoa(b.Requests[endpoint]))
+		}
+		for _, strategy := range strategies {
+			row = append(row, strconv.Itoa(b.StrategyMix[strategy]))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"loan-agent/domain"
+)
+
+const balanceTransferDebtName = "Traslado de saldo"
+
+type BalanceTransferService struct {
+	debtExitService *DebtExitService
+}
+
+func NewBalanceTransferService(debtExitService *DebtExitService) *BalanceTransferService {
+	return &BalanceTransferService{debtExitService: debtExitService}
+}
+
+// AnalyzeBalanceTransfer simula el portafolio con y sin trasladar los saldos
+// indicados y calcula el ahorro neto y el mes de equilibrio
+func (s *BalanceTransferService) AnalyzeBalanceTransfer(
+	input domain.BalanceTransferInput,
+) (domain.BalanceTransferResult, error) {
+
+	var v fieldValidator
+	language, err := validateLanguage(input.Language)
+	v.check("Language", err)
+	if input.Strategy != "snowball" && input.Strategy != "avalanche" {
+		v.add("Strategy", validationError("INVALID_STRATEGY"))
+	}
+	offer := input.Offer
+	if len(offer.DebtNames) == 0 {
+		v.add("Offer.DebtNames", validationError("NO_TRANSFER_DEBTS"))
+	}
+	if offer.FeePercent < 0 || offer.FeePercent > MaxTransferFeePercent {
+		v.add("Offer.FeePercent", validationError("INVALID_TRANSFER_FEE", MaxTransferFeePercent))
+	}
+	if err := v.err(); err != nil {
+		return domain.BalanceTransferResult{}, err
+	}
+
+	without, err := s.debtExitService.CalculateDebtExitPlan(domain.DebtExitInput{
+		Debts:                   input.Debts,
+		AvailableMonthlyPayment: input.AvailableMonthlyPayment,
+		Strategy:                input.Strategy,
+		IncludeExplanation:      withoutExplanation(),
+	})
+	if err != nil {
+		return domain.BalanceTransferResult{}, err
+	}
+
+	// Reemplazar las deudas trasladadas por una nueva deuda con la promoción;
+	// se usan las deudas convertidas a dólares para que las monedas cuadren
+	converted, err := convertDebtsToUSD(input.Debts)
+	if err != nil {
+		return domain.BalanceTransferResult{}, err
+	}
+	transferDebts := []domain.Debt{}
+	transferred := 0.0
+	minimums := 0.0
+	found := 0
+	for _, debt := range converted {
+		if slices.Contains(offer.DebtNames, debt.Name) {
+			transferred += debt.Amount
+			minimums += debt.MinimumPayment
+			found++
+			continue
+		}
+		transferDebts = append(transferDebts, debt)
+	}
+	if found != len(offer.DebtNames) {
+		return domain.BalanceTransferResult{}, validationError("UNKNOWN_TRANSFER_DEBT")
+	}
+
+	fee := transferred * offer.FeePercent / 100
+	minimumPayment := offer.MinimumPayment
+	if minimumPayment <= 0 {
+		minimumPayment = minimums
+	}
+	transferDebts = append(transferDebts, domain.Debt{
+		Name:              balanceTransferDebtName,
+		Amount:            transferred + fee,
+		InterestRate:      offer.StandardRate,
+		MinimumPayment:    minimumPayment,
+		PromoInterestRate: offer.PromoRate,
+		PromoMonths:       offer.PromoMonths,
+	})
+
+	with, err := s.debtExitService.CalculateDebtExitPlan(domain.DebtExitInput{
+		Debts:                   transferDebts,
+		AvailableMonthlyPayment: input.AvailableMonthlyPayment,
+		Strategy:                input.Strategy,
+		IncludeExplanation:      withoutExplanation(),
+	})
+	if err != nil {
+		return domain.BalanceTransferResult{}, wrapError(err, "offer.balance_transfer")
+	}
+
+	netSavings := planCost(without) - (planCost(with) + fee)
+
+	result := domain.BalanceTransferResult{
+		TransferredAmount: roundTo2Decimals(transferred),
+		TransferFee:       roundTo2Decimals(fee),
+		WithoutTransfer: domain.StrategyResult{
+			TotalInterestPaid: without.TotalInterestPaid,
+			TotalFeesPaid:     without.TotalFeesPaid,
+			MonthsToPayoff:    without.MonthsToPayoff,
+		},
+		WithTransfer: domain.StrategyResult{
+			TotalInterestPaid: with.TotalInterestPaid,
+			TotalFeesPaid:     with.TotalFeesPaid,
+			MonthsToPayoff:    with.MonthsToPayoff,
+		},
+		NetSavings:     roundTo2Decimals(netSavings),
+		SavesMoney:     netSavings > 0,
+		BreakEvenMonth: breakEvenMonth(without, with, without.TotalDebt),
+	}
+	result.Explanation = s.generateTransferExplanation(explanationOptions{Language: language}, result)
+
+	return result, nil
+}
+
+// planCosts devuelve el costo acumulado (intereses y comisiones) al cierre de
+// cada mes: lo pagado menos lo amortizado de la deuda original
+func planCosts(plan domain.DebtExitResult, originalDebt float64, months int) []float64 {
+	costs := make([]float64, months)
+	paid := 0.0
+	remaining := 0.0
+	for i := range costs {
+		if i < len(plan.MonthlyPlan) {
+			month := plan.MonthlyPlan[i]
+			paid += month.TotalPaid
+			remaining = 0
+			for _, payment := range month.Payments {
+				remaining += payment.RemainingBalance
+			}
+		}
+		costs[i] = paid - (originalDebt - remaining)
+	}
+	return costs
+}
+
+// breakEvenMonth devuelve el primer mes a partir del cual el costo acumulado
+// con traslado es siempre menor o igual al costo sin traslado, o 0 si nunca ocurre
+func breakEvenMonth(without, with domain.DebtExitResult, originalDebt float64) int {
+	months := max(len(without.MonthlyPlan), len(with.MonthlyPlan))
+	withoutCosts := planCosts(without, originalDebt, months)
+	withCosts := planCosts(with, originalDebt, months)
+
+	breakEven := 0
+	for i := months - 1; i >= 0; i-- {
+		if withCosts[i] > withoutCosts[i]+DebtBalanceTolerance {
+			break
+		}
+		breakEven = i + 1
+	}
+	return breakEven
+}
+
+func (s *BalanceTransferService) generateTransferExplanation(
+	opts explanationOptions,
+	result domain.BalanceTransferResult,
+) string {
+	var builder strings.Builder
+
+	builder.WriteString(opts.text("transfer.summary",
+		formatCurrency(result.TransferredAmount), formatCurrency(result.TransferFee),
+		formatCurrency(result.WithoutTransfer.TotalInterestPaid), formatCurrency(result.WithTransfer.TotalInterestPaid),
+		result.WithoutTransfer.MonthsToPayoff, result.WithTransfer.MonthsToPayoff))
+
+	if result.SavesMoney {
+		builder.WriteString(opts.text("transfer.saves", formatCurrency(result.NetSavings)))
+		if result.BreakEvenMonth > 0 {
+			builder.WriteString(opts.text("transfer.break_even", result.BreakEvenMonth))
+		}
+		builder.WriteString(opts.text("transfer.promo_tip"))
+	} else {
+		builder.WriteString(opts.text("transfer.costs", formatCurrency(-result.NetSavings)))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"math"
+	"strings"
+
+	"loan-agent/domain"
+)
+
+type ConsolidationService struct {
+	loanService     *LoanService
+	debtExitService *DebtExitService
+}
+
+func NewConsolidationService(
+	loanService *LoanService,
+	debtExitService *DebtExitService,
+) *ConsolidationService {
+	return &ConsolidationService{
+		loanService:     loanService,
+		debtExitService: debtExitService,
+	}
+}
+
+// CompareConsolidation compara consolidar las deudas en un préstamo contra
+// pagarlas con snowball o avalanche
+func (s *ConsolidationService) CompareConsolidation(
+	input domain.ConsolidationInput,
+) (domain.ConsolidationResult, error) {
+
+	var v fieldValidator
+	language, err := validateLanguage(input.Language)
+	v.check("Language", err)
+	offer := input.Offer
+	if offer.OriginationFeePercent < 0 || offer.OriginationFeePercent > MaxOriginationFeePercent {
+		v.add("Offer.OriginationFeePercent", validationError("INVALID_ORIGINATION_FEE", MaxOriginationFeePercent))
+	}
+	if offer.FlatFees < 0 {
+		v.add("Offer.FlatFees", validationError("INVALID_CLOSING_COSTS"))
+	}
+	if err := v.err(); err != nil {
+		return domain.ConsolidationResult{}, err
+	}
+
+	plan, err := s.debtExitService.CalculateDebtExitPlan(domain.DebtExitInput{
+		Debts:                   input.Debts,
+		AvailableMonthlyPayment: input.AvailableMonthlyPayment,
+		Strategy:                "compare",
+		IncludeExplanation:      withoutExplanation(),
+	})
+	if err != nil {
+		return domain.ConsolidationResult{}, err
+	}
+
+	// Si las comisiones se financian, el préstamo debe cubrir deuda más comisiones:
+	// monto = (deuda + fijos) / (1 - comisión%)
+	fees := plan.TotalDebt*offer.OriginationFeePercent/100 + offer.FlatFees
+	loanAmount := plan.TotalDebt
+	if offer.FinanceFees {
+		loanAmount = (plan.TotalDebt + offer.FlatFees) / (1 - offer.OriginationFeePercent/100)
+		fees = loanAmount - plan.TotalDebt
+	}
+
+	loan, err := s.loanService.quoteLoan(domain.LoanInput{
+		Amount:       roundTo2Decimals(loanAmount),
+		InterestRate: offer.InterestRate,
+		TermMonths:   offer.TermMonths,
+	})
+	if err != nil {
+		return domain.ConsolidationResult{}, wrapError(prefixFields(err, "Offer."), "offer.consolidation")
+	}
+
+	consolidation := domain.ConsolidationLoanResult{
+		LoanAmount:     roundTo2Decimals(loanAmount),
+		MonthlyPayment: loan.MonthlyPayment,
+		TotalInterest:  loan.TotalInterest,
+		TotalFees:      roundTo2Decimals(fees),
+		TotalCost:      roundTo2Decimals(loan.TotalInterest + fees),
+		MonthsToPayoff: offer.TermMonths,
+		Affordable:     loan.MonthlyPayment <= input.AvailableMonthlyPayment,
+	}
+
+	snowball := plan.Comparison.Snowball
+	avalanche := plan.Comparison.Avalanche
+
+	// El costo de mantener las deudas incluye sus cargos periódicos
+	bestStrategy, bestStrategyCost := "avalanche", avalanche.TotalInterestPaid+avalanche.TotalFeesPaid
+	if snowballCost := snowball.TotalInterestPaid + snowball.TotalFeesPaid; snowballCost < bestStrategyCost {
+		bestStrategy, bestStrategyCost = "snowball", snowballCost
+	}
+
+	recommendation := bestStrategy
+	savings := 0.0
+	if consolidation.Affordable && consolidation.TotalCost < bestStrategyCost {
+		recommendation = "consolidate"
+		savings = bestStrategyCost - consolidation.TotalCost
+	} else if consolidation.Affordable {
+		savings = consolidation.TotalCost - bestStrategyCost
+	}
+
+	result := domain.ConsolidationResult{
+		TotalDebt:      plan.TotalDebt,
+		Consolidation:  consolidation,
+		Snowball:       snowball,
+		Avalanche:      avalanche,
+		Recommendation: recommendation,
+		CostSavings:    roundTo2Decimals(math.Max(0, savings)),
+	}
+	result.Explanation = s.generateConsolidationExplanation(explanationOptions{Language: language}, result, bestStrategy)
+
+	return result, nil
+}
+
+func (s *ConsolidationService) generateConsolidationExplanation(
+	opts explanationOptions,
+	result domain.ConsolidationResult,
+	bestStrategy string,
+) string {
+	consolidation := result.Consolidation
+	best := result.Avalanche
+	bestName := "Avalanche"
+	if bestStrategy == "snowball" {
+		best = result.Snowball
+		bestName = "Snowball"
+	}
+
+	var builder strings.Builder
+	builder.WriteString(opts.text("consolidation.summary",
+		formatCurrency(consolidation.LoanAmount), consolidation.MonthsToPayoff, formatCurrency(consolidation.MonthlyPayment),
+		formatCurrency(consolidation.TotalCost), formatCurrency(consolidation.TotalInterest), formatCurrency(consolidation.TotalFees)))
+	builder.WriteString(opts.text("consolidation.strategy",
+		bestName, formatCurrency(best.TotalInterestPaid), best.MonthsToPayoff))
+
+	switch {
+	case !consolidation.Affordable:
+		builder.WriteString(opts.text("consolidation.unaffordable", bestName))
+	case result.Recommendation == "consolidate":
+		builder.WriteString(opts.text("consolidation.consolidate", formatCurrency(result.CostSavings), bestName))
+	default:
+		builder.WriteString(opts.text("consolidation.keep", formatCurrency(result.CostSavings), bestName))
+	}
+
+	return builder.String()
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 )
 
 const (
@@ -15,7 +17,58 @@ const (
 	MaxDebtPayoffMonths  = 600           // 50 años máximo para pagar deudas
 	DebtBalanceTolerance = 0.01          // tolerancia para considerar deuda pagada
 
-	MaxTermRangeMonths = 120 // máximo rango de términos a evaluar (10 años)
+	MaxInsuranceRate         = 5.0  // 5% mensual sobre saldo
+	MaxOriginationFeePercent = 20.0 // comisión de apertura máxima sobre el monto
+	MaxTransferFeePercent    = 20.0 // comisión máxima de traslado de saldo
+
+	MaxLumpSumsPerRequest     = 100   // máximo de pagos extra únicos por plan
+	MaxSnowflakesPerRequest   = 500   // máximo de micro pagos extra por plan
+	MaxPaymentStepsPerRequest = 50    // máximo de escalones de pago por plan
+	MaxPaymentGrowthPercent   = 100.0 // máximo crecimiento anual del pago
+
+	MaxFeesPerDebt = 10 // máximo de cargos periódicos por deuda
+
+	MaxHardshipMonthsPerRequest = 120 // máximo de meses de dificultad por plan
+
+	DefaultPlanPageSize = 12  // meses por página del plan mensual
+	MaxPlanPageSize     = 120 // máximo de meses por página
+
+	MaxAccrualDays = 366 // días máximos de devengo en una vista previa de pago
+
+	MaxRoundUpTransactions = 1000  // compras por mes en un perfil de redondeo
+	MaxRoundingUnit        = 100.0 // unidad de redondeo máxima
+	MaxRoundUpMultiplier   = 10.0  // multiplicador máximo del redondeo
+
+	MaxTermRangeMonths = 120 // máximo de incrementos de plazo a evaluar (10 años mes a mes)
+
+	MaxRateTiersPerRequest = 20 // máximo de tramos de tasa por recomendación
+
+	TermEvaluationWorkers = 8 // cálculos de plazo en paralelo por recomendación
+
+	LoanQuoteTTL = 10 * time.Minute // vigencia en el cache de los cálculos internos por plazo
+
+	ScoringWeightsTolerance = 0.001 // margen de redondeo al validar que los pesos sumen 1
+
+	// Umbrales de las sugerencias por datos probablemente mal ingresados
+	SuspiciousTermMonths    = 5      // plazos de hasta 5 "meses" suelen ser años
+	SuspiciousMonthlyRate   = 3.0    // tasas anuales de hasta 3% suelen ser mensuales
+	SuspiciousLoanAmount    = 1000.0 // montos menores a plazos largos suelen ser miles
+	SuspiciousAmountMinTerm = 36
+
+	MinBenchmarkSamples     = 30  // cálculos guardados necesarios para mostrar la mediana
+	BenchmarkMedianBandRate = 0.5 // puntos de tasa alrededor de la mediana que cuentan como "en línea"
+
+	MaxBorrowersPerRequest = 4 // deudor principal más co-deudores/fiadores
+
+	MaxTagsPerRequest = 10 // máximo de tags por cálculo
+	MaxTagLength      = 50 // máximo de caracteres por tag
+
+	AnalyticsRetention  = 90 * 24 * time.Hour // tiempo que se conservan las métricas
+	MaxAnalyticsRange   = 31 * 24 * time.Hour // máximo rango consultable de una vez
+	RemoteWriteInterval = time.Minute         // frecuencia de envío a Prometheus remote-write
+
+	HookTimeout          = 2 * time.Second // tiempo máximo de respuesta de un hook webhook
+	MaxHookResponseBytes = 1 << 20         // tamaño máximo del payload devuelto por un hook
 )
 
 func GetUSDToNIORate() float64 {
@@ -28,6 +81,334 @@ func GetUSDToNIORate() float64 {
 	return 36.5
 }
 
+// Market describe el país donde se despliega el servicio y su moneda local,
+// que se muestra junto a los montos en USD de las explicaciones
+type Market struct {
+	Country        string
+	CurrencyCode   string
+	CurrencySymbol string
+	DefaultUSDRate float64 // unidades de moneda local por USD si no se configura otra
+}
+
+// markets son los mercados centroamericanos soportados por código de país
+var markets = map[string]Market{
+	"NI": {Country: "Nicaragua", CurrencyCode: "NIO", CurrencySymbol: "C$", DefaultUSDRate: 36.5},
+	"CR": {Country: "Costa Rica", CurrencyCode: "CRC", CurrencySymbol: "₡", DefaultUSDRate: 505.0},
+	"HN": {Country: "Honduras", CurrencyCode: "HNL", CurrencySymbol: "L", DefaultUSDRate: 26.0},
+	"GT": {Country: "Guatemala", CurrencyCode: "GTQ", CurrencySymbol: "Q", DefaultUSDRate: 7.7},
+	"SV": {Country: "El Salvador", CurrencyCode: "USD", CurrencySymbol: "$", DefaultUSDRate: 1.0},
+	"PA": {Country: "Panamá", CurrencyCode: "USD", CurrencySymbol: "$", DefaultUSDRate: 1.0},
+}
+
+// GetMarket devuelve el mercado configurado con MARKET_COUNTRY (ej. CR);
+// por defecto Nicaragua
+func GetMarket() Market {
+	if market, ok := markets[strings.ToUpper(os.Getenv("MARKET_COUNTRY"))]; ok {
+		return market
+	}
+
+	return markets["NI"]
+}
+
+// GetMarketUSDRate devuelve las unidades de moneda local por USD, configurable
+// con MARKET_USD_RATE; en Nicaragua también se respeta USD_TO_NIO_RATE
+func GetMarketUSDRate() float64 {
+	if envRate := os.Getenv("MARKET_USD_RATE"); envRate != "" {
+		if parsedRate := parseFloat(envRate); parsedRate > 0 {
+			return parsedRate
+		}
+	}
+
+	market := GetMarket()
+	if market.CurrencyCode == "NIO" {
+		return GetUSDToNIORate()
+	}
+	return market.DefaultUSDRate
+}
+
+// defaultMaxLTV es el LTV máximo (%) por tipo de garantía
+var defaultMaxLTV = map[string]float64{
+	"real_estate": 80.0,
+	"vehicle":     70.0,
+	"deposit":     90.0,
+	"equipment":   60.0,
+}
+
+// GetMaxLTV devuelve el LTV máximo para el tipo de garantía, configurable
+// con MAX_LTV_<TIPO> (ej. MAX_LTV_REAL_ESTATE=85)
+func GetMaxLTV(collateralType string) (float64, bool) {
+	defaultLTV, ok := defaultMaxLTV[collateralType]
+	if !ok {
+		return 0, false
+	}
+
+	if envLTV := os.Getenv("MAX_LTV_" + strings.ToUpper(collateralType)); envLTV != "" {
+		if parsedLTV := parseFloat(envLTV); parsedLTV > 0 && parsedLTV <= 100 {
+			return parsedLTV, true
+		}
+	}
+
+	return defaultLTV, true
+}
+
+// GetLTVEnforcement indica si exceder el LTV rechaza el préstamo ("reject")
+// o solo agrega una advertencia ("warn")
+func GetLTVEnforcement() string {
+	if os.Getenv("LTV_ENFORCEMENT") == "warn" {
+		return "warn"
+	}
+
+	return "reject"
+}
+
+// defaultBorrowerIncomeWeight es la fracción del ingreso que cuenta para la
+// capacidad de pago según el rol del deudor
+var defaultBorrowerIncomeWeight = map[string]float64{
+	"primary":     1.0,
+	"co_borrower": 1.0,
+	"co_signer":   0.5,
+}
+
+// GetBorrowerIncomeWeight devuelve la ponderación de ingreso para el rol,
+// configurable con INCOME_WEIGHT_<ROL> (ej. INCOME_WEIGHT_CO_SIGNER=0.3)
+func GetBorrowerIncomeWeight(role string) (float64, bool) {
+	defaultWeight, ok := defaultBorrowerIncomeWeight[role]
+	if !ok {
+		return 0, false
+	}
+
+	if envWeight := os.Getenv("INCOME_WEIGHT_" + strings.ToUpper(role)); envWeight != "" {
+		if parsedWeight := parseFloat(envWeight); parsedWeight >= 0 && parsedWeight <= 1 {
+			return parsedWeight, true
+		}
+	}
+
+	return defaultWeight, true
+}
+
+// GetMaxDebtToIncome devuelve la relación deuda/ingreso máxima (%), configurable con MAX_DTI
+func GetMaxDebtToIncome() float64 {
+	if envDTI := os.Getenv("MAX_DTI"); envDTI != "" {
+		if parsedDTI := parseFloat(envDTI); parsedDTI > 0 && parsedDTI <= 100 {
+			return parsedDTI
+		}
+	}
+
+	return 40.0
+}
+
+// GetSLOLatencyBudget devuelve el presupuesto de latencia p95 por endpoint,
+// configurable con SLO_P95_LATENCY_MS
+func GetSLOLatencyBudget() time.Duration {
+	if envLatency := os.Getenv("SLO_P95_LATENCY_MS"); envLatency != "" {
+		if parsedLatency := parseFloat(envLatency); parsedLatency > 0 {
+			return time.Duration(parsedLatency * float64(time.Millisecond))
+		}
+	}
+
+	return 500 * time.Millisecond
+}
+
+// GetSLOMaxErrorRate devuelve la tasa de error (%) máxima por endpoint,
+// configurable con SLO_MAX_ERROR_RATE
+func GetSLOMaxErrorRate() float64 {
+	if envRate := os.Getenv("SLO_MAX_ERROR_RATE"); envRate != "" {
+		if parsedRate := parseFloat(envRate); parsedRate > 0 && parsedRate <= 100 {
+			return parsedRate
+		}
+	}
+
+	return 5.0
+}
+
+// GetAlertWebhookURL devuelve el webhook (p. ej. Slack) para alertas; vacío solo registra en el log
+func GetAlertWebhookURL() string {
+	return os.Getenv("ALERT_WEBHOOK_URL")
+}
+
+// GetDrainPeriod devuelve cuánto se sigue sirviendo tráfico tras fallar /readyz
+// antes de apagar el servidor, configurable con DRAIN_PERIOD_SECONDS
+func GetDrainPeriod() time.Duration {
+	if envPeriod := os.Getenv("DRAIN_PERIOD_SECONDS"); envPeriod != "" {
+		if parsedPeriod := parseFloat(envPeriod); parsedPeriod >= 0 {
+			return time.Duration(parsedPeriod * float64(time.Second))
+		}
+	}
+
+	return 15 * time.Second
+}
+
+// GetLoanSnapshotPath devuelve el archivo de snapshot del repositorio en memoria;
+// vacío desactiva los snapshots
+func GetLoanSnapshotPath() string {
+	return os.Getenv("LOAN_SNAPSHOT_PATH")
+}
+
+// GetLoanSnapshotInterval devuelve cada cuánto se escribe el snapshot,
+// configurable con LOAN_SNAPSHOT_INTERVAL_SECONDS
+func GetLoanSnapshotInterval() time.Duration {
+	if envInterval := os.Getenv("LOAN_SNAPSHOT_INTERVAL_SECONDS"); envInterval != "" {
+		if parsedInterval := parseFloat(envInterval); parsedInterval > 0 {
+			return time.Duration(parsedInterval * float64(time.Second))
+		}
+	}
+
+	return time.Minute
+}
+
+// GetPrometheusRemoteWriteURL devuelve el endpoint de remote-write; vacío desactiva el envío
+func GetPrometheusRemoteWriteURL() string {
+	return os.Getenv("PROMETHEUS_REMOTE_WRITE_URL")
+}
+
+// GetNIOAnnualDevaluation devuelve la devaluación anual (%) del córdoba frente
+// al dólar usada para proyectar deudas en córdobas, configurable con NIO_ANNUAL_DEVALUATION
+func GetNIOAnnualDevaluation() float64 {
+	if envDevaluation := os.Getenv("NIO_ANNUAL_DEVALUATION"); envDevaluation != "" {
+		if parsedDevaluation := parseFloat(envDevaluation); parsedDevaluation >= 0 {
+			return parsedDevaluation
+		}
+	}
+
+	return 0
+}
+
+// GetDuplicateRequestWindow devuelve durante cuánto se reconoce una request
+// repetida de un mismo cliente, configurable con DUPLICATE_REQUEST_WINDOW_SECONDS.
+// Por defecto es 0 (desactivada): con una ventana, las requests repetidas
+// reciben la respuesta anterior en lugar de un cálculo nuevo
+func GetDuplicateRequestWindow() time.Duration {
+	if envWindow := os.Getenv("DUPLICATE_REQUEST_WINDOW_SECONDS"); envWindow != "" {
+		if parsedWindow := parseFloat(envWindow); parsedWindow >= 0 {
+			return time.Duration(parsedWindow * float64(time.Second))
+		}
+	}
+
+	return 0
+}
+
+// GetMaxRequestBodyBytes devuelve el tamaño máximo aceptado para el body de
+// una request, configurable con MAX_REQUEST_BODY_BYTES
+func GetMaxRequestBodyBytes() int64 {
+	if envLimit := os.Getenv("MAX_REQUEST_BODY_BYTES"); envLimit != "" {
+		if parsedLimit := parseFloat(envLimit); parsedLimit > 0 {
+			return int64(parsedLimit)
+		}
+	}
+
+	return 256 << 10
+}
+
+// GetHookWebhookURL devuelve el webhook de la etapa, configurable con
+// HOOK_<ETAPA>_URL (ej. HOOK_PRE_VALIDATE_URL); vacío no registra hook
+func GetHookWebhookURL(stage HookStage) string {
+	return os.Getenv("HOOK_" + strings.ToUpper(string(stage)) + "_URL")
+}
+
+// RateLimitRouteGroups son los grupos de rutas (primer segmento del path) cuyo
+// límite por IP se puede configurar por separado
+var RateLimitRouteGroups = []string{"loan", "analytics", "slo", "graphql"}
+
+// rateLimitEnvName arma RATE_LIMIT_<SETTING> o, para un grupo de rutas,
+// RATE_LIMIT_<GRUPO>_<SETTING>
+func rateLimitEnvName(group, setting string) string {
+	if group == "" {
+		return "RATE_LIMIT_" + setting
+	}
+	return "RATE_LIMIT_" + strings.ToUpper(group) + "_" + setting
+}
+
+// GetRateLimitCapacity devuelve cuántas requests por ventana admite el rate
+// limiter por IP, configurable con RATE_LIMIT_CAPACITY y por grupo de rutas con
+// RATE_LIMIT_<GRUPO>_CAPACITY (ej. RATE_LIMIT_ANALYTICS_CAPACITY)
+func GetRateLimitCapacity(group string) int {
+	if envCapacity := os.Getenv(rateLimitEnvName(group, "CAPACITY")); envCapacity != "" {
+		if parsedCapacity := parseFloat(envCapacity); parsedCapacity >= 1 {
+			return int(parsedCapacity)
+		}
+	}
+	if group != "" {
+		return GetRateLimitCapacity("")
+	}
+
+	return 5
+}
+
+// GetRateLimitWindow devuelve la ventana del rate limiter, configurable con
+// RATE_LIMIT_WINDOW_SECONDS y por grupo con RATE_LIMIT_<GRUPO>_WINDOW_SECONDS
+func GetRateLimitWindow(group string) time.Duration {
+	if envWindow := os.Getenv(rateLimitEnvName(group, "WINDOW_SECONDS")); envWindow != "" {
+		if parsedWindow := parseFloat(envWindow); parsedWindow > 0 {
+			return time.Duration(parsedWindow * float64(time.Second))
+		}
+	}
+	if group != "" {
+		return GetRateLimitWindow("")
+	}
+
+	return time.Minute
+}
+
+// GetRateLimitCleanupInterval devuelve cada cuánto se limpian los buckets sin
+// uso, configurable con RATE_LIMIT_CLEANUP_INTERVAL_SECONDS
+func GetRateLimitCleanupInterval() time.Duration {
+	if envInterval := os.Getenv("RATE_LIMIT_CLEANUP_INTERVAL_SECONDS"); envInterval != "" {
+		if parsedInterval := parseFloat(envInterval); parsedInterval > 0 {
+			return time.Duration(parsedInterval * float64(time.Second))
+		}
+	}
+
+	return 30 * time.Minute
+}
+
+// GetRateLimitBucketTTL devuelve tras cuánto sin uso se elimina el bucket de
+// un cliente, configurable con RATE_LIMIT_BUCKET_TTL_SECONDS
+func GetRateLimitBucketTTL() time.Duration {
+	if envTTL := os.Getenv("RATE_LIMIT_BUCKET_TTL_SECONDS"); envTTL != "" {
+		if parsedTTL := parseFloat(envTTL); parsedTTL > 0 {
+			return time.Duration(parsedTTL * float64(time.Second))
+		}
+	}
+
+	return time.Hour
+}
+
+// GetIdempotencyKeyTTL devuelve durante cuánto se repite la respuesta guardada
+// de una Idempotency-Key, configurable con IDEMPOTENCY_KEY_TTL_SECONDS
+func GetIdempotencyKeyTTL() time.Duration {
+	if envTTL := os.Getenv("IDEMPOTENCY_KEY_TTL_SECONDS"); envTTL != "" {
+		if parsedTTL := parseFloat(envTTL); parsedTTL > 0 {
+			return time.Duration(parsedTTL * float64(time.Second))
+		}
+	}
+
+	return 24 * time.Hour
+}
+
+// GetJWTIssuer devuelve el emisor exigido en los JWT de usuario (JWT_ISSUER);
+// vacío no valida el emisor
+func GetJWTIssuer() string {
+	return os.Getenv("JWT_ISSUER")
+}
+
+// GetJWTAudience devuelve la audiencia exigida en los JWT de usuario (JWT_AUDIENCE)
+func GetJWTAudience() string {
+	return os.Getenv("JWT_AUDIENCE")
+}
+
+// GetJWKSURL devuelve la URL con las llaves públicas de los JWT RS* (JWT_JWKS_URL)
+func GetJWKSURL() string {
+	return os.Getenv("JWT_JWKS_URL")
+}
+
+// GetSandboxMode indica si toda la instancia corre en modo sandbox
+// (SANDBOX_MODE=true): los cálculos funcionan igual, pero no se guarda nada ni
+// se envían webhooks. Sin esta variable, el sandbox se activa por API key con
+// un tier Sandbox en RATE_LIMIT_TIERS.
+func GetSandboxMode() bool {
+	return os.Getenv("SANDBOX_MODE") == "true"
+}
+
 func parseFloat(s string) float64 {
 	var result float64
 	_, err := fmt.Sscanf(s, "%f", &result)
@@ -38,6 +419,11 @@ func parseFloat(s string) float64 {
 }
 
 func formatCurrency(amount float64) string {
-	nioAmount := amount * GetUSDToNIORate()
-	return fmt.Sprintf("$%.2f USD (C$%.2f NIO)", amount, nioAmount)
+	market := GetMarket()
+	if market.CurrencyCode == "USD" {
+		return fmt.Sprintf("$%.2f USD", amount)
+	}
+
+	localAmount := amount * GetMarketUSDRate()
+	return fmt.Sprintf("$%.2f USD (%s%.2f %s)", amount, market.CurrencySymbol, localAmount, market.CurrencyCode)
 }
@@ -1,200 +1,463 @@
 package service
 
 import (
-	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"sort"
 	"strings"
+	"time"
 
+	"loan-agent/clock"
 	"loan-agent/domain"
 )
 
+// planDateLayout es el formato de los meses de un plan fechado (AAAA-MM)
+const planDateLayout = "2006-01"
+
 type DebtExitService struct {
 	loanService *LoanService
+	clock       clock.Clock
 }
 
 func NewDebtExitService(loanService *LoanService) *DebtExitService {
 	return &DebtExitService{
 		loanService: loanService,
+		clock:       clock.Real{},
 	}
 }
 
+// SetClock reemplaza el reloj que define el mes de inicio por defecto de los planes
+func (s *DebtExitService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
 // CalculateDebtExitPlan calcula el plan de salida de deudas usando snowball o avalanche
 func (s *DebtExitService) CalculateDebtExitPlan(
 	input domain.DebtExitInput,
 ) (domain.DebtExitResult, error) {
 
-	if len(input.Debts) == 0 {
-		return domain.DebtExitResult{}, errors.New("no se proporcionaron deudas")
-	}
-	if len(input.Debts) > MaxDebtsPerRequest {
-		return domain.DebtExitResult{}, fmt.Errorf("número de deudas excede el máximo de %d", MaxDebtsPerRequest)
+	var v fieldValidator
+	switch {
+	case len(input.Debts) == 0:
+		v.add("Debts", validationError("NO_DEBTS"))
+	case len(input.Debts) > MaxDebtsPerRequest:
+		v.add("Debts", validationError("TOO_MANY_DEBTS", MaxDebtsPerRequest))
 	}
 	if input.AvailableMonthlyPayment <= 0 {
-		return domain.DebtExitResult{}, errors.New("pago mensual disponible inválido")
+		v.add("AvailableMonthlyPayment", validationError("INVALID_AVAILABLE_PAYMENT"))
 	}
 
-	// Validar nombres únicos
+	// La simulación trabaja en dólares; las deudas en córdobas se convierten
+	// antes de validar montos contra los máximos
+	debts := make([]domain.Debt, len(input.Debts))
 	debtNames := make(map[string]bool)
-	for _, debt := range input.Debts {
+	totalMinimumPayments := 0.0
+	rate := GetUSDToNIORate()
+	for i, debt := range input.Debts {
+		field := fmt.Sprintf("Debts[%d]", i)
 		if debt.Name == "" {
-			return domain.DebtExitResult{}, errors.New("nombre de deuda no puede estar vacío")
-		}
-		if debtNames[debt.Name] {
-			return domain.DebtExitResult{}, fmt.Errorf("nombre de deuda duplicado: %s", debt.Name)
+			v.add(field+".Name", validationError("EMPTY_DEBT_NAME"))
+		} else if debtNames[debt.Name] {
+			v.add(field+".Name", validationError("DUPLICATE_DEBT_NAME", debt.Name))
 		}
 		debtNames[debt.Name] = true
+
+		debt, err := debtInUSD(debt, rate)
+		if err != nil {
+			v.add(field+".Currency", err)
+			continue
+		}
+		debts[i] = debt
+
+		switch {
+		case debt.Amount <= 0:
+			v.add(field+".Amount", validationError("INVALID_DEBT_AMOUNT"))
+		case debt.Amount > MaxDebtAmount:
+			v.add(field+".Amount", validationError("DEBT_AMOUNT_TOO_LARGE", MaxDebtAmount))
+		}
+		switch {
+		case debt.InterestRate < 0:
+			v.add(field+".InterestRate", validationError("INVALID_DEBT_INTEREST_RATE"))
+		case debt.InterestRate > MaxInterestRate:
+			v.add(field+".InterestRate", validationError("DEBT_INTEREST_RATE_TOO_HIGH", MaxInterestRate))
+		}
+		if debt.MinimumPayment <= 0 {
+			v.add(field+".MinimumPayment", validationError("INVALID_MINIMUM_PAYMENT"))
+		} else {
+			// Validar que el pago mínimo sea razonable (al menos cubre el interés mensual
+			// a la tasa estándar, que es la que aplica al terminar la promoción)
+			monthlyInterest := debt.Amount * (debt.InterestRate / 100) / 12
+			if debt.MinimumPayment < monthlyInterest {
+				v.add(field+".MinimumPayment", validationError("MINIMUM_PAYMENT_BELOW_INTEREST", debt.Name, debt.MinimumPayment, monthlyInterest))
+			}
+		}
+		if debt.StartMonth < 0 || debt.StartMonth > MaxDebtPayoffMonths {
+			v.add(field+".StartMonth", validationError("INVALID_DEBT_START_MONTH", debt.Name))
+		}
+		if debt.PromoMonths < 0 || debt.PromoMonths > MaxDebtPayoffMonths {
+			v.add(field+".PromoMonths", validationError("INVALID_PROMO_MONTHS", debt.Name))
+		}
+		if debt.PromoInterestRate < 0 || debt.PromoInterestRate > MaxInterestRate {
+			v.add(field+".PromoInterestRate", validationError("INVALID_PROMO_RATE", debt.Name))
+		}
+		if !compoundingConventions[debt.Compounding] {
+			v.add(field+".Compounding", validationError("INVALID_COMPOUNDING", debt.Name))
+		}
+		v.check(field+".Fees", validateRecurringFees(debt))
+		totalMinimumPayments += debt.MinimumPayment
+	}
+	input.Debts = debts
+
+	if input.AvailableMonthlyPayment > 0 && totalMinimumPayments > input.AvailableMonthlyPayment {
+		v.add("AvailableMonthlyPayment", validationError("INSUFFICIENT_AVAILABLE_PAYMENT"))
 	}
 
 	strategies := map[string]bool{
 		"snowball":  true,
 		"avalanche": true,
+		"cfi":       true,
+		"weighted":  true,
 		"compare":   true,
 	}
 	if !strategies[input.Strategy] {
-		return domain.DebtExitResult{}, errors.New("estrategia inválida")
+		v.add("Strategy", validationError("INVALID_STRATEGY"))
+	}
+	v.check("StrategyWeights", validateStrategyWeights(input.Strategy, input.StrategyWeights))
+	if input.QuickWinMonths < 0 || input.QuickWinMonths > MaxDebtPayoffMonths {
+		v.add("QuickWinMonths", validationError("INVALID_QUICK_WIN_MONTHS"))
+	} else if input.QuickWinMonths > 0 && input.Strategy != "avalanche" {
+		v.add("QuickWinMonths", validationError("QUICK_WIN_REQUIRES_AVALANCHE"))
 	}
 
-	// Validar que todas las deudas sean válidas
-	totalMinimumPayments := 0.0
-	for _, debt := range input.Debts {
-		if debt.Amount <= 0 {
-			return domain.DebtExitResult{}, errors.New("monto de deuda inválido")
-		}
-		if debt.Amount > MaxDebtAmount {
-			return domain.DebtExitResult{}, fmt.Errorf("monto de deuda excede el máximo de $%.2f", MaxDebtAmount)
-		}
-		if debt.InterestRate < 0 {
-			return domain.DebtExitResult{}, errors.New("tasa de interés inválida")
-		}
-		if debt.InterestRate > MaxInterestRate {
-			return domain.DebtExitResult{}, fmt.Errorf("tasa de interés excede el máximo de %.2f%%", MaxInterestRate)
-		}
-		if debt.MinimumPayment <= 0 {
-			return domain.DebtExitResult{}, errors.New("pago mínimo inválido")
+	if len(input.LumpSums) > MaxLumpSumsPerRequest {
+		v.add("LumpSums", validationError("TOO_MANY_LUMP_SUMS", MaxLumpSumsPerRequest))
+	}
+	for i, lumpSum := range input.LumpSums {
+		field := fmt.Sprintf("LumpSums[%d]", i)
+		if lumpSum.Month < 1 || lumpSum.Month > MaxDebtPayoffMonths {
+			v.add(field+".Month", validationError("INVALID_LUMP_SUM_MONTH", lumpSum.Month))
 		}
-		// Validar que el pago mínimo sea razonable (al menos cubre el interés mensual)
-		monthlyInterest := debt.Amount * (debt.InterestRate / 100) / 12
-		if debt.MinimumPayment < monthlyInterest {
-			return domain.DebtExitResult{}, fmt.Errorf("pago mínimo de %s ($%.2f) es menor que el interés mensual ($%.2f)", debt.Name, debt.MinimumPayment, monthlyInterest)
+		if lumpSum.Amount <= 0 {
+			v.add(field+".Amount", validationError("INVALID_LUMP_SUM_AMOUNT"))
 		}
-		totalMinimumPayments += debt.MinimumPayment
 	}
 
-	if totalMinimumPayments > input.AvailableMonthlyPayment {
-		return domain.DebtExitResult{}, errors.New("el pago mensual disponible es insuficiente para cubrir los pagos mínimos")
+	v.check("PaymentGrowth", validatePaymentGrowth(input.PaymentGrowth, input.AvailableMonthlyPayment))
+	v.check("RoundUp", validateRoundUp(input.RoundUp))
+	v.check("HardshipMonths", validateHardshipMonths(input.HardshipMonths))
+	v.check("Snowflakes", validateSnowflakes(input.Snowflakes, debtNames))
+	language, err := validateLanguage(input.Language)
+	v.check("Language", err)
+	if !ReadingLevels[input.ReadingLevel] {
+		v.add("ReadingLevel", validationError("INVALID_READING_LEVEL"))
+	}
+	startDate, err := s.planStartDate(input.StartDate)
+	v.check("StartDate", err)
+	if input.MonthlyIncome < 0 {
+		v.add("MonthlyIncome", validationError("INVALID_MONTHLY_INCOME"))
+	}
+	v.check("PlanView", validatePlanView(input))
+	if err := v.err(); err != nil {
+		return domain.DebtExitResult{}, err
 	}
 
 	var result domain.DebtExitResult
 	var comparison *domain.Comparison
 
 	if input.Strategy == "compare" {
-		snowballResult := s.calculateStrategy(input, "snowball")
-		avalancheResult := s.calculateStrategy(input, "avalanche")
+		// Sin los planes de cada estrategia basta con sus totales; solo la
+		// estrategia elegida se simula mes a mes
+		simulate := func(strategy string) domain.DebtExitResult {
+			if input.IncludeComparisonPlans {
+				return s.calculateStrategy(input, strategy)
+			}
+			return s.strategyTotals(input, strategy, "")
+		}
+		snowballResult := simulate("snowball")
+		avalancheResult := simulate("avalanche")
+		cfiResult := simulate("cfi")
 
-		if avalancheResult.TotalInterestPaid < snowballResult.TotalInterestPaid {
+		// Se elige la estrategia de menor costo: intereses más cargos periódicos
+		result = snowballResult
+		if planCost(avalancheResult) < planCost(result) {
 			result = avalancheResult
-		} else {
-			result = snowballResult
+		}
+		if planCost(cfiResult) < planCost(result) {
+			result = cfiResult
+		}
+		if !input.IncludeComparisonPlans {
+			result = s.calculateStrategy(input, result.Strategy)
 		}
 
 		comparison = &domain.Comparison{
 			Snowball: domain.StrategyResult{
 				TotalInterestPaid: snowballResult.TotalInterestPaid,
+				TotalFeesPaid:     snowballResult.TotalFeesPaid,
 				MonthsToPayoff:    snowballResult.MonthsToPayoff,
 			},
 			Avalanche: domain.StrategyResult{
 				TotalInterestPaid: avalancheResult.TotalInterestPaid,
+				TotalFeesPaid:     avalancheResult.TotalFeesPaid,
 				MonthsToPayoff:    avalancheResult.MonthsToPayoff,
 			},
+			CFI: domain.StrategyResult{
+				TotalInterestPaid: cfiResult.TotalInterestPaid,
+				TotalFeesPaid:     cfiResult.TotalFeesPaid,
+				MonthsToPayoff:    cfiResult.MonthsToPayoff,
+			},
 		}
 		comparison.Savings.InterestSaved = roundTo2Decimals(
 			math.Max(0, snowballResult.TotalInterestPaid-avalancheResult.TotalInterestPaid),
 		)
 		comparison.Savings.MonthsSaved = snowballResult.MonthsToPayoff - avalancheResult.MonthsToPayoff
+		if input.IncludeComparisonPlans {
+			comparison.Snowball.MonthlyPlan = snowballResult.MonthlyPlan
+			comparison.Avalanche.MonthlyPlan = avalancheResult.MonthlyPlan
+			comparison.CFI.MonthlyPlan = cfiResult.MonthlyPlan
+		}
 		result.Comparison = comparison
+	} else if input.QuickWinMonths > 0 {
+		result = s.avalancheWithQuickWin(input)
 	} else {
 		result = s.calculateStrategy(input, input.Strategy)
 	}
 
-	// Generar explicación
-	result.Explanation = s.generateDebtExplanation(
-		result.Strategy,
-		result.TotalDebt,
-		result.TotalInterestPaid,
-		result.MonthsToPayoff,
-		input.Debts,
-		result.Comparison,
-	)
+	baseline := s.strategyTotals(input, "minimums", "")
+	result.Baseline = &domain.MinimumsBaseline{
+		TotalInterestPaid: baseline.TotalInterestPaid,
+		MonthsToPayoff:    baseline.MonthsToPayoff,
+		InterestSaved:     roundTo2Decimals(math.Max(0, baseline.TotalInterestPaid-result.TotalInterestPaid)),
+		MonthsSaved:       baseline.MonthsToPayoff - result.MonthsToPayoff,
+	}
+
+	result.Health = calculateDebtHealth(input)
+
+	if len(input.HardshipMonths) > 0 {
+		withoutHardship := input
+		withoutHardship.HardshipMonths = nil
+		focusDebt := ""
+		if result.QuickWin != nil {
+			focusDebt = result.QuickWin.PrioritizedDebt
+		}
+		regular := s.strategyTotals(withoutHardship, result.Strategy, focusDebt)
+		result.Hardship = &domain.HardshipImpact{
+			ExtraMonths:   result.MonthsToPayoff - regular.MonthsToPayoff,
+			ExtraInterest: roundTo2Decimals(result.TotalInterestPaid - regular.TotalInterestPaid),
+		}
+	}
+	result.StartDate = startDate.Format(planDateLayout)
+	result.PayoffDate = startDate.AddDate(0, result.MonthsToPayoff-1, 0).Format(planDateLayout)
+	datePlan(result.MonthlyPlan, startDate)
+	if result.Comparison != nil {
+		datePlan(result.Comparison.Snowball.MonthlyPlan, startDate)
+		datePlan(result.Comparison.Avalanche.MonthlyPlan, startDate)
+		datePlan(result.Comparison.CFI.MonthlyPlan, startDate)
+	}
+
+	// Generar explicación, salvo que se pidan solo los resultados numéricos
+	explain := func(lang string) string {
+		return s.generateDebtExplanation(
+			explanationOptions{Language: lang, ReadingLevel: input.ReadingLevel},
+			result.Strategy,
+			result.TotalDebt,
+			result.TotalInterestPaid,
+			result.TotalFeesPaid,
+			result.MonthsToPayoff,
+			input.Debts,
+			result.Comparison,
+			result.Baseline,
+			result.Health,
+			len(input.HardshipMonths),
+			result.Hardship,
+			input.StrategyWeights,
+			result.QuickWin,
+		)
+	}
+	if explanationRequested(input.IncludeExplanation) {
+		result.Explanation = explain(language)
+		if input.BilingualExplanation {
+			result.Explanations = map[string]string{}
+			for _, lang := range SupportedLanguages {
+				result.Explanations[lang] = explain(lang)
+			}
+		}
+	}
+
+	applyPlanView(&result, input)
 
 	return result, nil
 }
 
+// calculateStrategy simula el plan mes a mes. La estrategia "minimums" es la
+// línea base: cada deuda recibe solo su mínimo y el resto del presupuesto no se usa.
 func (s *DebtExitService) calculateStrategy(
 	input domain.DebtExitInput,
 	strategy string,
 ) domain.DebtExitResult {
+	return s.simulateStrategy(input, strategy, "")
+}
+
+// simulateStrategy es calculateStrategy con la deuda focusDebt (si hay)
+// adelantada al primer lugar del orden de la estrategia
+func (s *DebtExitService) simulateStrategy(
+	input domain.DebtExitInput,
+	strategy string,
+	focusDebt string,
+) domain.DebtExitResult {
+	minimumsOnly := strategy == "minimums"
 
 	// Crear copia de las deudas para trabajar
 	debts := make([]domain.Debt, len(input.Debts))
 	copy(debts, input.Debts)
 
-	if strategy == "snowball" {
-		sort.Slice(debts, func(i, j int) bool {
-			return debts[i].Amount < debts[j].Amount
-		})
-	} else {
-		sort.Slice(debts, func(i, j int) bool {
-			return debts[i].InterestRate > debts[j].InterestRate
-		})
+	sortDebtsForStrategy(debts, strategy, input.StrategyWeights)
+	debts = prioritizeDebt(debts, focusDebt)
+
+	// El estado de cada deuda vive en slices indexados por su posición en el
+	// orden de la estrategia; los buffers por mes se reutilizan entre meses
+	count := len(debts)
+	balances := make([]float64, count)
+	for i, debt := range debts {
+		// Las deudas futuras se activan al llegar a su mes de inicio
+		if debt.StartMonth <= 1 {
+			balances[i] = debt.Amount
+		}
 	}
 
-	balances := make(map[string]float64)
-	for _, debt := range debts {
-		balances[debt.Name] = debt.Amount
+	roundUpExtra := estimateRoundUpExtra(input.RoundUp)
+
+	lumpSums := make(map[int]float64)
+	for _, lumpSum := range input.LumpSums {
+		lumpSums[lumpSum.Month] += lumpSum.Amount
+	}
+
+	snowflakes := make(map[int][]domain.Snowflake)
+	// Orden en que cada snowflake recorre las deudas: su deuda (si indica una)
+	// primero y luego el orden de la estrategia
+	snowflakeOrders := make(map[string][]int)
+	for _, snowflake := range input.Snowflakes {
+		snowflakes[snowflake.Month] = append(snowflakes[snowflake.Month], snowflake)
+		if _, ok := snowflakeOrders[snowflake.DebtName]; ok {
+			continue
+		}
+		order := make([]int, 0, count)
+		for i, debt := range debts {
+			if debt.Name == snowflake.DebtName {
+				order = append(order, i)
+			}
+		}
+		for i, debt := range debts {
+			if debt.Name != snowflake.DebtName {
+				order = append(order, i)
+			}
+		}
+		snowflakeOrders[snowflake.DebtName] = order
+	}
+
+	hardships := make(map[int]string)
+	for _, hardship := range input.HardshipMonths {
+		hardships[hardship.Month] = hardship.Mode
 	}
 
-	monthlyPlan := []domain.MonthlyPlan{}
+	monthlyPlan := make([]domain.MonthlyPlan, 0, 60)
 	totalInterestPaid := 0.0
+	debtInterest := make([]float64, count)
+	debtPaid := make([]float64, count)
+	debtFees := make([]float64, count)
+	totalFeesPaid := 0.0
+	payoffMonths := make([]int, count)
+	// Interés de saldo promedio diario cobrado al cierre del mes anterior
+	postedInterest := make([]float64, count)
+
+	interests := make([]float64, count)
+	openingBalances := make([]float64, count)
+	opened := make([]bool, count)
+	// Interés que cubren los pagos del mes antes de abonar a capital
+	interestDue := make([]float64, count)
+	// Posición del pago de cada deuda en el mes (-1 sin pago) y deuda de cada pago
+	paymentIndex := make([]int, count)
+	paymentDebts := make([]int, 0, count)
 	month := 0
 
 	// Simular pagos mes a mes hasta que todas las deudas estén pagadas
 	for {
 		month++
-		available := input.AvailableMonthlyPayment
-		payments := []domain.MonthlyPayment{}
+		for i, debt := range debts {
+			if debt.StartMonth > 1 && debt.StartMonth == month {
+				balances[i] = debt.Amount * currencyFactor(debt, month)
+			} else if debt.Currency == "NIO" && month > 1 {
+				// La devaluación del córdoba reduce el valor en dólares del saldo
+				balances[i] *= currencyFactor(debt, 2)
+			}
+		}
+		// Los cargos periódicos se suman al saldo de las deudas activas
+		for i, debt := range debts {
+			if balances[i] <= DebtBalanceTolerance || len(debt.Fees) == 0 {
+				continue
+			}
+			fee := recurringFeesForMonth(debt, month) * currencyFactor(debt, month)
+			balances[i] += fee
+			debtFees[i] += fee
+			totalFeesPaid += fee
+		}
+
+		available := monthlyBudget(input, month) + lumpSums[month] + roundUpExtra
+		if minimumsOnly {
+			available = math.Inf(1)
+		}
+		// En un mes de dificultad no hay pagos extra; con "skip" no hay pagos
+		hardship := hardships[month]
+		if hardship == "minimums" {
+			available = monthlyBudget(input, month)
+		} else if hardship == "skip" {
+			available = 0
+		}
 		totalPaid := 0.0
 
-		interestMap := make(map[string]float64)
-		for _, debt := range debts {
-			if balances[debt.Name] <= 0 {
+		activeDebts := 0
+		for i, debt := range debts {
+			interests[i] = 0
+			interestDue[i] = 0
+			opened[i] = false
+			paymentIndex[i] = -1
+			if balances[i] <= 0 {
+				continue
+			}
+			activeDebts++
+			openingBalances[i] = balances[i]
+			opened[i] = true
+			// Con saldo promedio diario el interés se cobra al cierre del mes
+			if debt.Compounding == "average_daily_balance" {
+				interestDue[i] = postedInterest[i]
 				continue
 			}
 			// Calcular interés del mes sobre el balance inicial
-			monthlyRate := (debt.InterestRate / 100) / 12
-			interest := balances[debt.Name] * monthlyRate
-			interestMap[debt.Name] = interest
+			interest := periodInterest(debt, balances[i], month)
+			interests[i] = interest
+			interestDue[i] = interest
 			totalInterestPaid += interest
+			debtInterest[i] += interest
 		}
+		payments := make([]domain.MonthlyPayment, 0, activeDebts)
+		paymentDebts = paymentDebts[:0]
 
 		// Pagar mínimos (debe cubrir al menos el interés)
-		for _, debt := range debts {
-			if balances[debt.Name] <= 0 {
+		for i, debt := range debts {
+			if balances[i] <= 0 {
 				continue
 			}
 
-			interest := interestMap[debt.Name]
+			interest := interests[i]
 			// El pago mínimo debe cubrir al menos el interés mensual
 			// Si el pago mínimo es menor que el interés, usar el interés como mínimo
-			minRequiredPayment := debt.MinimumPayment
+			minRequiredPayment := debt.MinimumPayment * currencyFactor(debt, month)
 			if minRequiredPayment < interest {
 				minRequiredPayment = interest
 			}
 
 			// Calcular el pago máximo posible (balance + interés)
-			maxPossiblePayment := balances[debt.Name] + interest
+			maxPossiblePayment := balances[i] + interest
 
 			// El pago debe ser al menos el mínimo requerido, pero no más del máximo posible
 			payment := minRequiredPayment
@@ -212,61 +475,138 @@ func (s *DebtExitService) calculateStrategy(
 				if principalPaid < 0 {
 					principalPaid = 0
 				}
-				balances[debt.Name] -= principalPaid
-				if balances[debt.Name] < 0 {
-					balances[debt.Name] = 0
+				balances[i] -= principalPaid
+				if balances[i] < 0 {
+					balances[i] = 0
 				}
 
+				paymentIndex[i] = len(payments)
+				paymentDebts = append(paymentDebts, i)
 				payments = append(payments, domain.MonthlyPayment{
 					DebtName:         debt.Name,
 					Payment:          roundTo2Decimals(payment),
-					RemainingBalance: roundTo2Decimals(balances[debt.Name]),
+					RemainingBalance: roundTo2Decimals(balances[i]),
 				})
 
 				available -= payment
 				totalPaid += payment
+				debtPaid[i] += payment
+			}
+		}
+
+		// Aplicar excedente a las deudas activas en el orden de la estrategia;
+		// si la primera se liquida, el sobrante pasa a la siguiente
+		for i := range debts {
+			if available <= 0 || minimumsOnly || hardship != "" {
+				break
+			}
+			if balances[i] <= 0 || paymentIndex[i] < 0 {
+				continue
+			}
+
+			extraPayment := available
+			if extraPayment > balances[i] {
+				extraPayment = balances[i]
 			}
+
+			entry := &payments[paymentIndex[i]]
+			entry.Payment = roundTo2Decimals(entry.Payment + extraPayment)
+			balances[i] -= extraPayment
+			if balances[i] < 0 {
+				balances[i] = 0
+			}
+			entry.RemainingBalance = roundTo2Decimals(balances[i])
+			totalPaid += extraPayment
+			available -= extraPayment
+			debtPaid[i] += extraPayment
 		}
 
-		// Aplicar excedente a la primera deuda activa según estrategia
-		if available > 0 {
-			for _, debt := range debts {
-				if balances[debt.Name] > 0 && available > 0 {
-					extraPayment := available
-					if extraPayment > balances[debt.Name] {
-						extraPayment = balances[debt.Name]
-					}
-
-					for i := range payments {
-						if payments[i].DebtName == debt.Name {
-							payments[i].Payment = roundTo2Decimals(payments[i].Payment + extraPayment)
-							balances[debt.Name] -= extraPayment
-							if balances[debt.Name] < 0 {
-								balances[debt.Name] = 0
-							}
-							payments[i].RemainingBalance = roundTo2Decimals(balances[debt.Name])
-							totalPaid += extraPayment
-							available -= extraPayment
-							break
-						}
-					}
+		// Snowflakes: van a su deuda o a la deuda foco y el sobrante sigue el
+		// orden de la estrategia. Con "skip" el interés se capitaliza abajo, lo
+		// que equivale a que el snowflake cubra primero el interés.
+		for _, snowflake := range snowflakes[month] {
+			if minimumsOnly {
+				break
+			}
+			remaining := snowflake.Amount
+			for _, i := range snowflakeOrders[snowflake.DebtName] {
+				if remaining <= 0 {
 					break
 				}
+				if balances[i] <= 0 {
+					continue
+				}
+				extraPayment := math.Min(remaining, balances[i])
+				balances[i] -= extraPayment
+				if paymentIndex[i] < 0 {
+					paymentIndex[i] = len(payments)
+					paymentDebts = append(paymentDebts, i)
+					payments = append(payments, domain.MonthlyPayment{DebtName: debts[i].Name})
+				}
+				entry := &payments[paymentIndex[i]]
+				entry.Payment = roundTo2Decimals(entry.Payment + extraPayment)
+				entry.RemainingBalance = roundTo2Decimals(balances[i])
+				totalPaid += extraPayment
+				debtPaid[i] += extraPayment
+				remaining -= extraPayment
+			}
+		}
+
+		// Sin pagos, el interés del mes se capitaliza
+		if hardship == "skip" {
+			for i := range debts {
+				balances[i] += interests[i]
 			}
 		}
 
+		// Saldo promedio diario: los pagos se asumen a mitad del ciclo, así que el
+		// saldo promedio es el promedio entre apertura y cierre; el interés se
+		// agrega al saldo y se paga el mes siguiente, como en un estado de cuenta.
+		// Si la deuda se liquida en el mes no se cobra interés residual.
+		for i, debt := range debts {
+			if !opened[i] || debt.Compounding != "average_daily_balance" {
+				continue
+			}
+			postedInterest[i] = 0
+			if balances[i] <= DebtBalanceTolerance {
+				continue
+			}
+			interest := periodInterest(debt, (openingBalances[i]+balances[i])/2, month)
+			postedInterest[i] = interest
+			balances[i] += interest
+			totalInterestPaid += interest
+			debtInterest[i] += interest
+			if paymentIndex[i] >= 0 {
+				payments[paymentIndex[i]].RemainingBalance = roundTo2Decimals(balances[i])
+			}
+		}
+
+		// Composición de cada pago: primero cubre el interés del mes, el resto es capital
+		monthInterest := 0.0
+		for j := range payments {
+			interest := roundTo2Decimals(math.Min(payments[j].Payment, interestDue[paymentDebts[j]]))
+			payments[j].Interest = interest
+			payments[j].Principal = roundTo2Decimals(payments[j].Payment - interest)
+			monthInterest += interest
+		}
+
 		monthlyPlan = append(monthlyPlan, domain.MonthlyPlan{
-			Month:     month,
-			Payments:  payments,
-			TotalPaid: roundTo2Decimals(totalPaid),
+			Month:          month,
+			Hardship:       hardship,
+			Payments:       payments,
+			TotalPaid:      roundTo2Decimals(totalPaid),
+			TotalInterest:  roundTo2Decimals(monthInterest),
+			TotalPrincipal: roundTo2Decimals(totalPaid - monthInterest),
 		})
 
 		// Verificar si todas las deudas están pagadas
 		allPaid := true
-		for _, debt := range debts {
-			if balances[debt.Name] > DebtBalanceTolerance {
+		for i, debt := range debts {
+			if payoffMonths[i] == 0 && debt.StartMonth <= month && balances[i] <= DebtBalanceTolerance {
+				payoffMonths[i] = month
+			}
+			if balances[i] > DebtBalanceTolerance || debt.StartMonth > month {
 				allPaid = false
-				break
 			}
 		}
 
@@ -276,7 +616,7 @@ func (s *DebtExitService) calculateStrategy(
 
 		// Límite de seguridad para evitar loops infinitos
 		if month > MaxDebtPayoffMonths {
-			log.Printf("Warning: debt payoff calculation reached maximum months limit (%d)", MaxDebtPayoffMonths)
+			slog.Warn("debt payoff calculation reached maximum months limit", "max_months", MaxDebtPayoffMonths)
 			break
 		}
 	}
@@ -286,113 +626,491 @@ func (s *DebtExitService) calculateStrategy(
 		totalDebt += debt.Amount
 	}
 
+	// Resumen por deuda en el orden de la estrategia
+	summaries := make([]domain.DebtSummary, 0, count)
+	for i, debt := range debts {
+		summaries = append(summaries, domain.DebtSummary{
+			DebtName:          debt.Name,
+			PayoffMonth:       payoffMonths[i],
+			TotalInterestPaid: roundTo2Decimals(debtInterest[i]),
+			TotalFeesPaid:     roundTo2Decimals(debtFees[i]),
+			TotalPaid:         roundTo2Decimals(debtPaid[i]),
+		})
+	}
+
 	return domain.DebtExitResult{
 		Strategy:          strategy,
+		Currency:          "USD",
 		TotalDebt:         roundTo2Decimals(totalDebt),
+		RoundUpExtra:      roundTo2Decimals(roundUpExtra),
 		TotalInterestPaid: roundTo2Decimals(totalInterestPaid),
+		TotalFeesPaid:     roundTo2Decimals(totalFeesPaid),
 		MonthsToPayoff:    month,
+		DebtSummaries:     summaries,
 		MonthlyPlan:       monthlyPlan,
 	}
 }
 
+// planStartDate devuelve el primer día del mes 1 del plan: el mes indicado
+// (AAAA-MM) o el mes actual según el reloj del servicio
+func (s *DebtExitService) planStartDate(value string) (time.Time, error) {
+	if value == "" {
+		now := s.clock.Now()
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	start, err := time.Parse(planDateLayout, value)
+	if err != nil {
+		return time.Time{}, validationError("INVALID_START_DATE")
+	}
+	return start, nil
+}
+
+// datePlan asigna a cada mes del plan su fecha AAAA-MM a partir del mes de inicio
+func datePlan(plan []domain.MonthlyPlan, startDate time.Time) {
+	for i := range plan {
+		plan[i].Date = startDate.AddDate(0, plan[i].Month-1, 0).Format(planDateLayout)
+	}
+}
+
+func validateStrategyWeights(strategy string, weights *domain.StrategyWeights) error {
+	if strategy != "weighted" {
+		return nil
+	}
+	if weights == nil {
+		return validationError("STRATEGY_WEIGHTS_REQUIRED")
+	}
+	if weights.InterestRate < 0 || weights.Balance < 0 || weights.InterestRate+weights.Balance <= 0 {
+		return validationError("INVALID_STRATEGY_WEIGHTS")
+	}
+	return nil
+}
+
+// compoundingConventions lista cómo puede capitalizar el interés una deuda;
+// vacío equivale a "simple_monthly" (tasa mensual sobre el saldo de apertura)
+var compoundingConventions = map[string]bool{
+	"":                      true,
+	"simple_monthly":        true,
+	"daily":                 true,
+	"average_daily_balance": true,
+}
+
+func validateSnowflakes(snowflakes []domain.Snowflake, debtNames map[string]bool) error {
+	if len(snowflakes) > MaxSnowflakesPerRequest {
+		return validationError("TOO_MANY_SNOWFLAKES", MaxSnowflakesPerRequest)
+	}
+	for _, snowflake := range snowflakes {
+		if snowflake.Month < 1 || snowflake.Month > MaxDebtPayoffMonths {
+			return validationError("INVALID_SNOWFLAKE_MONTH", snowflake.Month)
+		}
+		if snowflake.Amount <= 0 {
+			return validationError("INVALID_SNOWFLAKE_AMOUNT")
+		}
+		if snowflake.DebtName != "" && !debtNames[snowflake.DebtName] {
+			return validationError("UNKNOWN_SNOWFLAKE_DEBT", snowflake.DebtName)
+		}
+	}
+	return nil
+}
+
+// prioritizeDebt devuelve las deudas en el orden de la estrategia con la
+// deuda indicada (si hay) al frente
+func prioritizeDebt(debts []domain.Debt, target string) []domain.Debt {
+	if target == "" {
+		return debts
+	}
+	ordered := make([]domain.Debt, 0, len(debts))
+	for _, debt := range debts {
+		if debt.Name == target {
+			ordered = append(ordered, debt)
+		}
+	}
+	for _, debt := range debts {
+		if debt.Name != target {
+			ordered = append(ordered, debt)
+		}
+	}
+	return ordered
+}
+
+func validateHardshipMonths(hardships []domain.HardshipMonth) error {
+	if len(hardships) > MaxHardshipMonthsPerRequest {
+		return validationError("TOO_MANY_HARDSHIP_MONTHS", MaxHardshipMonthsPerRequest)
+	}
+	seen := make(map[int]bool)
+	for _, hardship := range hardships {
+		if hardship.Month < 1 || hardship.Month > MaxDebtPayoffMonths || seen[hardship.Month] {
+			return validationError("INVALID_HARDSHIP_MONTH", hardship.Month)
+		}
+		if hardship.Mode != "minimums" && hardship.Mode != "skip" {
+			return validationError("INVALID_HARDSHIP_MODE")
+		}
+		seen[hardship.Month] = true
+	}
+	return nil
+}
+
+// planCost es el costo financiero de un plan: intereses más cargos periódicos
+func planCost(plan domain.DebtExitResult) float64 {
+	return plan.TotalInterestPaid + plan.TotalFeesPaid
+}
+
+func validateRecurringFees(debt domain.Debt) error {
+	if len(debt.Fees) > MaxFeesPerDebt {
+		return validationError("TOO_MANY_DEBT_FEES", debt.Name, MaxFeesPerDebt)
+	}
+	for _, fee := range debt.Fees {
+		if fee.Amount <= 0 {
+			return validationError("INVALID_FEE_AMOUNT", debt.Name)
+		}
+		if fee.EveryMonths < 1 || fee.EveryMonths > MaxDebtPayoffMonths {
+			return validationError("INVALID_FEE_FREQUENCY", debt.Name)
+		}
+		if fee.FirstMonth < 0 || fee.FirstMonth > MaxDebtPayoffMonths {
+			return validationError("INVALID_FEE_START_MONTH", debt.Name)
+		}
+	}
+	return nil
+}
+
+// recurringFeesForMonth suma los cargos periódicos de la deuda que se cobran en el mes
+func recurringFeesForMonth(debt domain.Debt, month int) float64 {
+	total := 0.0
+	for _, fee := range debt.Fees {
+		first := max(fee.FirstMonth, 1)
+		if month >= first && (month-first)%fee.EveryMonths == 0 {
+			total += fee.Amount
+		}
+	}
+	return total
+}
+
+// sortDebtsForStrategy ordena las deudas según la prioridad de pago de la
+// estrategia; weights solo se usa con "weighted"
+func sortDebtsForStrategy(debts []domain.Debt, strategy string, weights *domain.StrategyWeights) {
+	switch strategy {
+	case "snowball":
+		sort.Slice(debts, func(i, j int) bool {
+			return debts[i].Amount < debts[j].Amount
+		})
+	case "cfi":
+		// El índice de flujo de caja (saldo / pago mínimo) más bajo primero:
+		// liquidar esas deudas libera más pago mínimo por dólar abonado
+		sort.Slice(debts, func(i, j int) bool {
+			return debts[i].Amount/debts[i].MinimumPayment < debts[j].Amount/debts[j].MinimumPayment
+		})
+	case "weighted":
+		// La tasa y el saldo se normalizan contra el máximo del portafolio; un
+		// saldo menor suma más puntos, como en Snowball
+		maxRate, maxAmount := 0.0, 0.0
+		for _, debt := range debts {
+			maxRate = math.Max(maxRate, debt.InterestRate)
+			maxAmount = math.Max(maxAmount, debt.Amount)
+		}
+		score := func(debt domain.Debt) float64 {
+			rateScore := 0.0
+			if maxRate > 0 {
+				rateScore = debt.InterestRate / maxRate
+			}
+			return weights.InterestRate*rateScore + weights.Balance*(1-debt.Amount/maxAmount)
+		}
+		sort.Slice(debts, func(i, j int) bool {
+			return score(debts[i]) > score(debts[j])
+		})
+	default:
+		// Avalanche ordena por la tasa estándar: una promoción temporal no
+		// cambia cuál deuda será la más cara a lo largo del plan
+		sort.Slice(debts, func(i, j int) bool {
+			return debts[i].InterestRate > debts[j].InterestRate
+		})
+	}
+}
+
+// convertDebtsToUSD devuelve una copia de las deudas con los montos en
+// córdobas convertidos a dólares al tipo de cambio actual
+func convertDebtsToUSD(debts []domain.Debt) ([]domain.Debt, error) {
+	converted := make([]domain.Debt, len(debts))
+	rate := GetUSDToNIORate()
+
+	for i, debt := range debts {
+		debt, err := debtInUSD(debt, rate)
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = debt
+	}
+	return converted, nil
+}
+
+// debtInUSD devuelve la deuda con los montos en dólares al tipo de cambio indicado
+func debtInUSD(debt domain.Debt, rate float64) (domain.Debt, error) {
+	switch debt.Currency {
+	case "", "USD":
+		debt.Currency = "USD"
+	case "NIO":
+		debt.Amount /= rate
+		debt.MinimumPayment /= rate
+		fees := make([]domain.RecurringFee, len(debt.Fees))
+		for j, fee := range debt.Fees {
+			fee.Amount /= rate
+			fees[j] = fee
+		}
+		debt.Fees = fees
+	default:
+		return domain.Debt{}, validationError("INVALID_CURRENCY", debt.Name)
+	}
+	return debt, nil
+}
+
+// currencyFactor devuelve el factor que lleva un monto en dólares de una deuda
+// en córdobas del mes 1 al mes indicado, según la devaluación anual configurada
+func currencyFactor(debt domain.Debt, month int) float64 {
+	if debt.Currency != "NIO" || month <= 1 {
+		return 1
+	}
+	devaluation := GetNIOAnnualDevaluation()
+	return math.Pow(1+devaluation/100, -float64(month-1)/12)
+}
+
+// periodInterest calcula el interés de un mes sobre el saldo según la
+// convención de capitalización de la deuda
+func periodInterest(debt domain.Debt, balance float64, month int) float64 {
+	rate := interestRateForMonth(debt, month) / 100
+	if debt.Compounding == "daily" {
+		return balance * (math.Pow(1+rate/365, 365.0/12) - 1)
+	}
+	return balance * rate / 12
+}
+
+// interestRateForMonth devuelve la tasa anual vigente en el mes: la promocional
+// durante los primeros PromoMonths meses y la estándar después
+func interestRateForMonth(debt domain.Debt, month int) float64 {
+	if month <= debt.PromoMonths {
+		return debt.PromoInterestRate
+	}
+	return debt.InterestRate
+}
+
+// validatePaymentGrowth valida el crecimiento del pago; los escalones deben ir
+// en meses crecientes y nunca bajar del pago inicial
+func validatePaymentGrowth(growth *domain.PaymentGrowth, initialPayment float64) error {
+	if growth == nil {
+		return nil
+	}
+	if growth.AnnualPercent < 0 || growth.AnnualPercent > MaxPaymentGrowthPercent {
+		return validationError("INVALID_PAYMENT_GROWTH", MaxPaymentGrowthPercent)
+	}
+	if len(growth.Steps) > MaxPaymentStepsPerRequest {
+		return validationError("TOO_MANY_PAYMENT_STEPS", MaxPaymentStepsPerRequest)
+	}
+
+	lastMonth := 0
+	for _, step := range growth.Steps {
+		if step.Month <= lastMonth || step.Month > MaxDebtPayoffMonths {
+			return validationError("INVALID_PAYMENT_STEP_MONTH", step.Month)
+		}
+		if step.Amount < initialPayment {
+			return validationError("PAYMENT_STEP_BELOW_INITIAL")
+		}
+		lastMonth = step.Month
+	}
+	return nil
+}
+
+func validateRoundUp(roundUp *domain.RoundUpProfile) error {
+	if roundUp == nil {
+		return nil
+	}
+	if roundUp.TransactionsPerMonth <= 0 || roundUp.TransactionsPerMonth > MaxRoundUpTransactions {
+		return validationError("INVALID_ROUND_UP_TRANSACTIONS", MaxRoundUpTransactions)
+	}
+	if roundUp.RoundingUnit <= 0 || roundUp.RoundingUnit > MaxRoundingUnit {
+		return validationError("INVALID_ROUNDING_UNIT", MaxRoundingUnit)
+	}
+	if roundUp.Multiplier < 0 || roundUp.Multiplier > MaxRoundUpMultiplier {
+		return validationError("INVALID_ROUND_UP_MULTIPLIER", MaxRoundUpMultiplier)
+	}
+	return nil
+}
+
+// estimateRoundUpExtra estima el aporte mensual de los redondeos. Con montos de
+// compra arbitrarios la fracción redondeada se distribuye de forma uniforme,
+// así que cada compra aporta en promedio la mitad de la unidad.
+func estimateRoundUpExtra(roundUp *domain.RoundUpProfile) float64 {
+	if roundUp == nil {
+		return 0
+	}
+	multiplier := roundUp.Multiplier
+	if multiplier == 0 {
+		multiplier = 1
+	}
+	return float64(roundUp.TransactionsPerMonth) * roundUp.RoundingUnit / 2 * multiplier
+}
+
+// monthlyBudget devuelve el pago disponible del mes: el monto del último
+// escalón alcanzado, con el crecimiento anual compuesto cada 12 meses desde
+// ese escalón (o desde el mes 1 si no se alcanzó ninguno)
+func monthlyBudget(input domain.DebtExitInput, month int) float64 {
+	budget := input.AvailableMonthlyPayment
+	growth := input.PaymentGrowth
+	if growth == nil {
+		return budget
+	}
+
+	since := 1
+	for _, step := range growth.Steps {
+		if step.Month > month {
+			break
+		}
+		budget, since = step.Amount, step.Month
+	}
+
+	years := (month - since) / 12
+	if growth.AnnualPercent > 0 && years > 0 {
+		budget *= math.Pow(1+growth.AnnualPercent/100, float64(years))
+	}
+	return budget
+}
+
 func (s *DebtExitService) generateDebtExplanation(
+	opts explanationOptions,
 	strategy string,
-	totalDebt, totalInterest float64,
+	totalDebt, totalInterest, totalFees float64,
 	months int,
 	debts []domain.Debt,
 	comparison *domain.Comparison,
+	baseline *domain.MinimumsBaseline,
+	health domain.DebtHealthMetrics,
+	hardshipMonths int,
+	hardship *domain.HardshipImpact,
+	weights *domain.StrategyWeights,
+	quickWin *domain.QuickWinResult,
 ) string {
-	strategyName := "Snowball (Bola de Nieve)"
-	strategyTip := "Ideal si necesitas ver resultados rápidos para mantenerte motivado. Cada deuda pagada libera capital que puedes aplicar a la siguiente."
-	if strategy == "avalanche" {
-		strategyName = "Avalanche (Avalancha)"
-		strategyTip = "Ideal si tu objetivo principal es minimizar el costo financiero total. Requiere disciplina pero maximiza el ahorro."
-	}
+	strategyName := opts.text("debt.strategy." + strategy)
+	strategyTip := opts.text("debt.tip." + strategy)
 
-	totalCost := totalDebt + totalInterest
+	totalCost := totalDebt + totalInterest + totalFees
 	var builder strings.Builder
 
-	builder.WriteString(fmt.Sprintf("La estrategia %s te permitirá liquidar todas tus deudas en %d meses (%.1f años). ", strategyName, months, float64(months)/12.0))
-	builder.WriteString(fmt.Sprintf("Tu deuda inicial es %s y pagarás %s en intereses, para un costo total de %s. ",
+	builder.WriteString(opts.text("debt.summary", strategyName, months, float64(months)/12.0))
+	if strategy == "weighted" {
+		total := weights.InterestRate + weights.Balance
+		builder.WriteString(opts.text("debt.weights", weights.InterestRate/total*100, weights.Balance/total*100))
+	}
+	builder.WriteString(opts.text("debt.cost",
 		formatCurrency(totalDebt), formatCurrency(totalInterest), formatCurrency(totalCost)))
+	if totalFees > 0 {
+		builder.WriteString(opts.text("debt.fees", formatCurrency(totalFees)))
+	}
+
+	builder.WriteString(opts.text("debt.health."+health.RiskLevel,
+		health.WeightedAverageAPR, health.FirstMonthInterestShare))
+	if health.DebtToIncome > 0 {
+		builder.WriteString(opts.text("debt.health.dti", health.DebtToIncome))
+	}
 
 	// Orden de pago
 	sortedDebts := make([]domain.Debt, len(debts))
 	copy(sortedDebts, debts)
-	if strategy == "snowball" {
-		sort.Slice(sortedDebts, func(i, j int) bool {
-			return sortedDebts[i].Amount < sortedDebts[j].Amount
-		})
-	} else {
-		sort.Slice(sortedDebts, func(i, j int) bool {
-			return sortedDebts[i].InterestRate > sortedDebts[j].InterestRate
-		})
+	sortDebtsForStrategy(sortedDebts, strategy, weights)
+	if quickWin != nil {
+		sortedDebts = prioritizeDebt(sortedDebts, quickWin.PrioritizedDebt)
 	}
 
-	builder.WriteString(fmt.Sprintf("\n\nCon %s, el orden de pago es:\n", strategyName))
+	builder.WriteString(opts.text("debt.order", strategyName))
 	for i, debt := range sortedDebts {
-		builder.WriteString(fmt.Sprintf("%d. %s: %s (%.2f%% anual)\n",
+		if debt.Currency == "NIO" {
+			builder.WriteString(opts.text("debt.order.nio",
+				i+1, debt.Name, formatCurrency(debt.Amount), debt.InterestRate))
+			continue
+		}
+		if debt.StartMonth > 1 {
+			builder.WriteString(opts.text("debt.order.future",
+				i+1, debt.Name, formatCurrency(debt.Amount), debt.StartMonth, debt.InterestRate))
+			continue
+		}
+		if debt.PromoMonths > 0 {
+			builder.WriteString(opts.text("debt.order.promo",
+				i+1, debt.Name, formatCurrency(debt.Amount), debt.PromoInterestRate, debt.PromoMonths, debt.InterestRate))
+			continue
+		}
+		builder.WriteString(opts.text("debt.order.standard",
 			i+1, debt.Name, formatCurrency(debt.Amount), debt.InterestRate))
 	}
 
 	// Comparación si existe
 	if comparison != nil {
-		builder.WriteString(s.buildComparisonText(strategy, comparison, months))
+		builder.WriteString(s.buildComparisonText(opts, strategy, comparison))
+	}
+
+	if baseline != nil && (baseline.InterestSaved > 0 || baseline.MonthsSaved > 0) {
+		builder.WriteString(opts.text("debt.baseline",
+			baseline.MonthsToPayoff, formatCurrency(baseline.TotalInterestPaid), formatCurrency(baseline.InterestSaved), baseline.MonthsSaved))
 	}
 
-	builder.WriteString(fmt.Sprintf("\n\nRecomendación: %s", strategyTip))
+	if quickWin != nil {
+		if !quickWin.Satisfied {
+			builder.WriteString(opts.text("debt.quick_win.unmet", quickWin.Months))
+		} else if quickWin.PrioritizedDebt != "" {
+			builder.WriteString(opts.text("debt.quick_win",
+				quickWin.Months, quickWin.PrioritizedDebt, quickWin.FirstPayoffMonth, formatCurrency(quickWin.ExtraInterest)))
+		}
+	}
+
+	if hardship != nil {
+		builder.WriteString(opts.text("debt.hardship", hardshipMonths, hardship.ExtraMonths, formatCurrency(hardship.ExtraInterest)))
+	}
+
+	builder.WriteString(opts.text("debt.recommendation", strategyTip))
 
 	return builder.String()
 }
 
-func (s *DebtExitService) buildComparisonText(strategy string, comparison *domain.Comparison, monthsToPayoff int) string {
+func (s *DebtExitService) buildComparisonText(opts explanationOptions, strategy string, comparison *domain.Comparison) string {
 	monthsDiff := comparison.Savings.MonthsSaved
 	interestSaved := comparison.Savings.InterestSaved
 	var text string
 
+	if strategy == "cfi" {
+		return opts.text("debt.cfi_vs_others",
+			comparison.Snowball.MonthsToPayoff, formatCurrency(comparison.Snowball.TotalInterestPaid),
+			comparison.Avalanche.MonthsToPayoff, formatCurrency(comparison.Avalanche.TotalInterestPaid))
+	}
+
 	if strategy == "snowball" {
 		if interestSaved > 0 {
 			if monthsDiff > 0 {
-				text = fmt.Sprintf("\n\nComparado con Avalanche, pagarás %s más en intereses y tomará %d meses más, pero ofrece mayor motivación psicológica.",
-					formatCurrency(interestSaved), monthsDiff)
+				text = opts.text("debt.vs_avalanche.more_both", formatCurrency(interestSaved), monthsDiff)
 			} else if monthsDiff < 0 {
-				text = fmt.Sprintf("\n\nComparado con Avalanche, terminarás %d meses antes pero pagarás %s más en intereses. Esto ocurre porque pagar deudas pequeñas primero libera capital más rápido, aunque puede resultar en un costo total mayor.",
-					-monthsDiff, formatCurrency(interestSaved))
+				text = opts.text("debt.vs_avalanche.sooner", -monthsDiff, formatCurrency(interestSaved))
 			} else {
-				text = fmt.Sprintf("\n\nComparado con Avalanche, pagarás %s más en intereses en el mismo tiempo, pero con mayor motivación psicológica.",
-					formatCurrency(interestSaved))
+				text = opts.text("debt.vs_avalanche.same_time", formatCurrency(interestSaved))
 			}
 		} else {
 			if monthsDiff > 0 {
-				text = fmt.Sprintf("\n\nComparado con Avalanche, pagarás los mismos intereses pero tomará %d meses más, aunque ofrece mayor motivación psicológica.",
-					monthsDiff)
+				text = opts.text("debt.vs_avalanche.later", monthsDiff)
 			} else if monthsDiff < 0 {
-				text = fmt.Sprintf("\n\nComparado con Avalanche, pagarás los mismos intereses y terminarás %d meses antes, combinando motivación con eficiencia temporal.",
-					-monthsDiff)
+				text = opts.text("debt.vs_avalanche.faster", -monthsDiff)
 			}
 		}
 	} else {
 		if interestSaved > 0 {
 			if monthsDiff > 0 {
-				text = fmt.Sprintf("\n\nComparado con Snowball, ahorrarás %s en intereses y terminarás %d meses antes, minimizando tu costo financiero total.",
-					formatCurrency(interestSaved), monthsDiff)
+				text = opts.text("debt.vs_snowball.save_both", formatCurrency(interestSaved), monthsDiff)
 			} else if monthsDiff < 0 {
-				text = fmt.Sprintf("\n\nComparado con Snowball, ahorrarás %s en intereses aunque tomará %d meses más. Esto ocurre porque priorizar deudas con mayor interés minimiza el costo total, aunque puede tomar más tiempo.",
-					formatCurrency(interestSaved), -monthsDiff)
+				text = opts.text("debt.vs_snowball.later", formatCurrency(interestSaved), -monthsDiff)
 			} else {
-				text = fmt.Sprintf("\n\nComparado con Snowball, ahorrarás %s en intereses en el mismo tiempo, optimizando el costo financiero.",
-					formatCurrency(interestSaved))
+				text = opts.text("debt.vs_snowball.same_time", formatCurrency(interestSaved))
 			}
 		} else {
 			if monthsDiff > 0 {
-				text = fmt.Sprintf("\n\nComparado con Snowball, pagarás los mismos intereses y terminarás %d meses antes, minimizando el tiempo total.",
-					monthsDiff)
+				text = opts.text("debt.vs_snowball.faster", monthsDiff)
 			} else if monthsDiff < 0 {
-				text = fmt.Sprintf("\n\nComparado con Snowball, pagarás los mismos intereses pero tomará %d meses más, aunque minimiza el costo financiero.",
-					-monthsDiff)
+				text = opts.text("debt.vs_snowball.slower", -monthsDiff)
 			}
 		}
 	}
 
+	text += opts.text("debt.vs_cfi", comparison.CFI.MonthsToPayoff, formatCurrency(comparison.CFI.TotalInterestPaid))
+
 	return text
 }
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"loan-agent/domain"
+)
+
+// Umbrales de la clasificación de riesgo de la cartera de deudas
+const (
+	highRiskInterestShare   = 50.0 // % del presupuesto del mes 1 en intereses
+	mediumRiskInterestShare = 25.0
+	highRiskAPR             = 36.0 // tasa anual promedio ponderada
+	mediumRiskAPR           = 20.0
+)
+
+// calculateDebtHealth calcula las métricas de salud de las deudas activas en el
+// mes 1. Las deudas deben venir ya convertidas a dólares.
+func calculateDebtHealth(input domain.DebtExitInput) domain.DebtHealthMetrics {
+	totalBalance := 0.0
+	weightedRate := 0.0
+	firstMonthInterest := 0.0
+	minimumPayments := 0.0
+
+	for _, debt := range input.Debts {
+		minimumPayments += debt.MinimumPayment
+		if debt.StartMonth > 1 {
+			continue
+		}
+		totalBalance += debt.Amount
+		weightedRate += debt.Amount * debt.InterestRate
+		firstMonthInterest += periodInterest(debt, debt.Amount, 1)
+	}
+
+	metrics := domain.DebtHealthMetrics{}
+	if totalBalance > 0 {
+		metrics.WeightedAverageAPR = roundTo2Decimals(weightedRate / totalBalance)
+	}
+	if budget := monthlyBudget(input, 1); budget > 0 {
+		metrics.FirstMonthInterestShare = roundTo2Decimals(firstMonthInterest / budget * 100)
+	}
+	if input.MonthlyIncome > 0 {
+		metrics.DebtToIncome = roundTo2Decimals(minimumPayments / input.MonthlyIncome * 100)
+	}
+	metrics.RiskLevel = classifyDebtRisk(metrics)
+
+	return metrics
+}
+
+// classifyDebtRisk clasifica el riesgo según el indicador más desfavorable
+func classifyDebtRisk(metrics domain.DebtHealthMetrics) string {
+	maxDTI := GetMaxDebtToIncome()
+
+	switch {
+	case metrics.DebtToIncome > maxDTI,
+		metrics.FirstMonthInterestShare >= highRiskInterestShare,
+		metrics.WeightedAverageAPR >= highRiskAPR:
+		return "high"
+	case metrics.DebtToIncome > maxDTI*0.75,
+		metrics.FirstMonthInterestShare >= mediumRiskInterestShare,
+		metrics.WeightedAverageAPR >= mediumRiskAPR:
+		return "medium"
+	}
+	return "low"
+}
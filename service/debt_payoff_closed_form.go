@@ -0,0 +1,246 @@
+package service
+
+import (
+	"log/slog"
+	"math"
+
+	"loan-agent/domain"
+)
+
+// closedFormEligible indica si los únicos eventos de la simulación son las
+// liquidaciones de deudas: sin promociones, córdobas, cargos, deudas futuras,
+// saldo promedio diario, meses de dificultad, pagos extra ni cambios de
+// presupuesto. Entre liquidaciones cada deuda paga un monto fijo y su saldo
+// sigue la fórmula de una anualidad.
+func closedFormEligible(input domain.DebtExitInput) bool {
+	if len(input.HardshipMonths) > 0 || len(input.Snowflakes) > 0 || len(input.LumpSums) > 0 || input.PaymentGrowth != nil {
+		return false
+	}
+	for _, debt := range input.Debts {
+		if debt.PromoMonths > 0 || debt.Currency == "NIO" || len(debt.Fees) > 0 ||
+			debt.StartMonth > 1 || debt.Compounding == "average_daily_balance" {
+			return false
+		}
+	}
+	return true
+}
+
+// strategyTotals devuelve los totales de simulateStrategy (meses, intereses y
+// resumen por deuda) sin el plan mes a mes. Cuando la entrada lo permite los
+// calcula de forma analítica entre liquidaciones; si no, simula mes a mes.
+func (s *DebtExitService) strategyTotals(
+	input domain.DebtExitInput,
+	strategy string,
+	focusDebt string,
+) domain.DebtExitResult {
+	if !closedFormEligible(input) {
+		return s.simulateStrategy(input, strategy, focusDebt)
+	}
+	return closedFormStrategy(input, strategy, focusDebt)
+}
+
+// closedFormStrategy reproduce simulateStrategy para una entrada que cumple
+// closedFormEligible. Mientras ninguna deuda se liquida, cada deuda paga su
+// mínimo y la primera del orden recibe además el excedente, así que el saldo
+// después de k meses es B·g^k − P·(g^k − 1)/r con g = 1 + r. Se salta de
+// golpe hasta dos meses antes de la próxima liquidación y los meses cercanos a
+// cada liquidación se simulan igual que en el loop, de modo que los meses de
+// pago coinciden y los totales solo difieren por redondeo de punto flotante.
+func closedFormStrategy(
+	input domain.DebtExitInput,
+	strategy string,
+	focusDebt string,
+) domain.DebtExitResult {
+	minimumsOnly := strategy == "minimums"
+
+	debts := make([]domain.Debt, len(input.Debts))
+	copy(debts, input.Debts)
+	sortDebtsForStrategy(debts, strategy, input.StrategyWeights)
+	debts = prioritizeDebt(debts, focusDebt)
+
+	count := len(debts)
+	balances := make([]float64, count)
+	rates := make([]float64, count)
+	for i, debt := range debts {
+		balances[i] = debt.Amount
+		// Sin promoción la tasa mensual es la misma todos los meses
+		rates[i] = periodInterest(debt, 1, 1)
+	}
+
+	roundUpExtra := estimateRoundUpExtra(input.RoundUp)
+	budget := input.AvailableMonthlyPayment + roundUpExtra
+
+	totalInterestPaid := 0.0
+	debtInterest := make([]float64, count)
+	debtPaid := make([]float64, count)
+	payoffMonths := make([]int, count)
+	interests := make([]float64, count)
+	paying := make([]bool, count)
+	payments := make([]float64, count)
+	month := 0
+
+	// step simula un mes con las mismas reglas que simulateStrategy
+	step := func() {
+		month++
+		available := budget
+		if minimumsOnly {
+			available = math.Inf(1)
+		}
+
+		for i, debt := range debts {
+			interests[i] = 0
+			paying[i] = false
+			if balances[i] <= 0 {
+				continue
+			}
+			interest := periodInterest(debt, balances[i], month)
+			interests[i] = interest
+			totalInterestPaid += interest
+			debtInterest[i] += interest
+		}
+
+		for i, debt := range debts {
+			if balances[i] <= 0 {
+				continue
+			}
+			interest := interests[i]
+			payment := math.Min(math.Max(debt.MinimumPayment, interest), balances[i]+interest)
+			if payment > available {
+				payment = available
+			}
+			if payment > 0 {
+				balances[i] = math.Max(0, balances[i]-math.Max(0, payment-interest))
+				paying[i] = true
+				available -= payment
+				debtPaid[i] += payment
+			}
+		}
+
+		for i := range debts {
+			if available <= 0 || minimumsOnly {
+				break
+			}
+			if balances[i] <= 0 || !paying[i] {
+				continue
+			}
+			extraPayment := math.Min(available, balances[i])
+			balances[i] = math.Max(0, balances[i]-extraPayment)
+			available -= extraPayment
+			debtPaid[i] += extraPayment
+		}
+	}
+
+	// jumpMonths devuelve cuántos meses se pueden saltar sin llegar a ninguna
+	// liquidación y deja en payments el pago fijo de cada deuda activa
+	jumpMonths := func() int {
+		minimums := 0.0
+		first := -1
+		for i, debt := range debts {
+			if balances[i] <= 0 {
+				continue
+			}
+			// El saldo debe bajar todos los meses para que el mínimo siga
+			// cubriendo el interés
+			if debt.MinimumPayment <= balances[i]*rates[i] {
+				return 0
+			}
+			payments[i] = debt.MinimumPayment
+			minimums += debt.MinimumPayment
+			if first < 0 {
+				first = i
+			}
+		}
+		if first < 0 {
+			return 0
+		}
+		// Con "minimums" el presupuesto no limita los pagos
+		if !minimumsOnly {
+			if minimums > budget {
+				return 0
+			}
+			payments[first] += budget - minimums
+		}
+
+		months := math.Inf(1)
+		for i := range debts {
+			if balances[i] <= 0 {
+				continue
+			}
+			// Meses (continuos) hasta que el saldo llega a cero
+			remaining := balances[i] / payments[i]
+			if rates[i] > 0 {
+				remaining = math.Log(payments[i]/(payments[i]-rates[i]*balances[i])) / math.Log1p(rates[i])
+			}
+			months = math.Min(months, remaining)
+		}
+		jump := math.Min(math.Floor(months)-2, float64(MaxDebtPayoffMonths-month-1))
+		return int(math.Max(0, jump))
+	}
+
+	for {
+		if jump := jumpMonths(); jump > 0 {
+			for i := range debts {
+				if balances[i] <= 0 {
+					continue
+				}
+				paid := float64(jump) * payments[i]
+				remaining := balances[i] - paid
+				if rates[i] > 0 {
+					growth := math.Pow(1+rates[i], float64(jump))
+					remaining = balances[i]*growth - payments[i]*(growth-1)/rates[i]
+				}
+				// Lo pagado que no redujo el saldo fue interés
+				interest := paid - (balances[i] - remaining)
+				totalInterestPaid += interest
+				debtInterest[i] += interest
+				debtPaid[i] += paid
+				balances[i] = remaining
+			}
+			month += jump
+		}
+
+		step()
+
+		allPaid := true
+		for i := range debts {
+			if payoffMonths[i] == 0 && balances[i] <= DebtBalanceTolerance {
+				payoffMonths[i] = month
+			}
+			if balances[i] > DebtBalanceTolerance {
+				allPaid = false
+			}
+		}
+		if allPaid {
+			break
+		}
+		if month > MaxDebtPayoffMonths {
+			slog.Warn("debt payoff calculation reached maximum months limit", "max_months", MaxDebtPayoffMonths)
+			break
+		}
+	}
+
+	totalDebt := 0.0
+	for _, debt := range input.Debts {
+		totalDebt += debt.Amount
+	}
+
+	summaries := make([]domain.DebtSummary, 0, count)
+	for i, debt := range debts {
+		summaries = append(summaries, domain.DebtSummary{
+			DebtName:          debt.Name,
+			PayoffMonth:       payoffMonths[i],
+			TotalInterestPaid: roundTo2Decimals(debtInterest[i]),
+			TotalPaid:         roundTo2Decimals(debtPaid[i]),
+		})
+	}
+
+	return domain.DebtExitResult{
+		Strategy:          strategy,
+		Currency:          "USD",
+		TotalDebt:         roundTo2Decimals(totalDebt),
+		RoundUpExtra:      roundTo2Decimals(roundUpExtra),
+		TotalInterestPaid: roundTo2Decimals(totalInterestPaid),
+		MonthsToPayoff:    month,
+		DebtSummaries:     summaries,
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"loan-agent/domain"
+)
+
+// closedFormInputs genera un conjunto fijo de carteras que cumplen
+// closedFormEligible: de 1 a 50 deudas, tasas de 0 a 60%, capitalización
+// mensual o diaria y presupuestos desde justo los mínimos hasta varias veces más
+func closedFormInputs() []domain.DebtExitInput {
+	random := rand.New(rand.NewSource(4814))
+	compoundings := []string{"", "simple_monthly", "daily"}
+
+	inputs := []domain.DebtExitInput{}
+	for n := 0; n < 300; n++ {
+		count := 1 + random.Intn(50)
+		if n%3 == 0 {
+			count = 1 + random.Intn(5)
+		}
+		debts := make([]domain.Debt, count)
+		minimums := 0.0
+		for i := range debts {
+			amount := float64(100+random.Intn(50000)) + float64(random.Intn(100))/100
+			rate := float64(random.Intn(6000)) / 100
+			if random.Intn(10) == 0 {
+				rate = 0
+			}
+			// Entre justo el interés (más un centavo) y un 10% del saldo
+			interest := amount * rate / 100 / 12
+			minimum := interest + 0.01 + amount*random.Float64()*0.1
+			debts[i] = domain.Debt{
+				Name:           fmt.Sprintf("deuda-%d", i),
+				Amount:         amount,
+				InterestRate:   rate,
+				MinimumPayment: float64(int(minimum*100)+1) / 100,
+				Compounding:    compoundings[random.Intn(len(compoundings))],
+			}
+			minimums += debts[i].MinimumPayment
+		}
+
+		input := domain.DebtExitInput{
+			Debts:                   debts,
+			AvailableMonthlyPayment: float64(int(minimums*(1+random.Float64()*3)*100)) / 100,
+		}
+		if n%4 == 0 {
+			input.AvailableMonthlyPayment = minimums
+		}
+		if n%7 == 0 {
+			input.RoundUp = &domain.RoundUpProfile{TransactionsPerMonth: 30, RoundingUnit: 1}
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+func TestClosedFormStrategyMatchesSimulation(t *testing.T) {
+	// Varias carteras llegan al límite de meses; el aviso no aporta aquí
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+
+	s := NewDebtExitService(nil)
+	strategies := []string{"minimums", "snowball", "avalanche", "cfi"}
+
+	compared := 0
+	for n, input := range closedFormInputs() {
+		if !closedFormEligible(input) {
+			t.Fatalf("input %d should be eligible for the closed form", n)
+		}
+		for _, strategy := range strategies {
+			focusDebts := []string{""}
+			if strategy == "avalanche" {
+				focusDebts = append(focusDebts, input.Debts[len(input.Debts)-1].Name)
+			}
+			for _, focusDebt := range focusDebts {
+				simulated := s.simulateStrategy(input, strategy, focusDebt)
+				simulated.MonthlyPlan = nil
+				closedForm := closedFormStrategy(input, strategy, focusDebt)
+				if !reflect.DeepEqual(simulated, closedForm) {
+					t.Errorf("input %d, strategy %q, focus %q:\nsimulated   %+v\nclosed form %+v", n, strategy, focusDebt, simulated, closedForm)
+				}
+				compared++
+			}
+		}
+	}
+	t.Logf("compared %d simulations", compared)
+}
+
+func TestClosedFormEligible(t *testing.T) {
+	base := domain.DebtExitInput{
+		Debts:                   []domain.Debt{{Name: "tarjeta", Amount: 1000, InterestRate: 24, MinimumPayment: 50}},
+		AvailableMonthlyPayment: 100,
+	}
+	if !closedFormEligible(base) {
+		t.Fatal("a plain portfolio should be eligible")
+	}
+
+	withDebt := func(change func(*domain.Debt)) domain.DebtExitInput {
+		input := base
+		input.Debts = []domain.Debt{base.Debts[0]}
+		change(&input.Debts[0])
+		return input
+	}
+	withInput := func(change func(*domain.DebtExitInput)) domain.DebtExitInput {
+		input := base
+		change(&input)
+		return input
+	}
+
+	cases := map[string]domain.DebtExitInput{
+		"promo":          withDebt(func(d *domain.Debt) { d.PromoMonths = 6 }),
+		"nio":            withDebt(func(d *domain.Debt) { d.Currency = "NIO" }),
+		"fees":           withDebt(func(d *domain.Debt) { d.Fees = []domain.RecurringFee{{Amount: 5}} }),
+		"future debt":    withDebt(func(d *domain.Debt) { d.StartMonth = 3 }),
+		"average daily":  withDebt(func(d *domain.Debt) { d.Compounding = "average_daily_balance" }),
+		"hardship":       withInput(func(i *domain.DebtExitInput) { i.HardshipMonths = []domain.HardshipMonth{{Month: 2, Mode: "skip"}} }),
+		"snowflakes":     withInput(func(i *domain.DebtExitInput) { i.Snowflakes = []domain.Snowflake{{Month: 2, Amount: 10}} }),
+		"lump sums":      withInput(func(i *domain.DebtExitInput) { i.LumpSums = []domain.LumpSum{{Month: 2, Amount: 10}} }),
+		"payment growth": withInput(func(i *domain.DebtExitInput) { i.PaymentGrowth = &domain.PaymentGrowth{AnnualPercent: 5} }),
+	}
+	for name, input := range cases {
+		if closedFormEligible(input) {
+			t.Errorf("%s should fall back to the monthly simulation", name)
+		}
+	}
+}
+
+// benchmarkPortfolio es el caso del pedido original: 50 deudas que tardan
+// cientos de meses en liquidarse
+func benchmarkPortfolio() domain.DebtExitInput {
+	debts := make([]domain.Debt, 50)
+	minimums := 0.0
+	for i := range debts {
+		amount := 2000 + float64(i)*750
+		debts[i] = domain.Debt{
+			Name:           fmt.Sprintf("deuda-%d", i),
+			Amount:         amount,
+			InterestRate:   12 + float64(i%20),
+			MinimumPayment: amount * 0.02,
+		}
+		minimums += debts[i].MinimumPayment
+	}
+	return domain.DebtExitInput{Debts: debts, AvailableMonthlyPayment: minimums * 1.1}
+}
+
+func BenchmarkSimulateStrategy(b *testing.B) {
+	s := NewDebtExitService(nil)
+	input := benchmarkPortfolio()
+	for b.Loop() {
+		s.simulateStrategy(input, "avalanche", "")
+	}
+}
+
+func BenchmarkClosedFormStrategy(b *testing.B) {
+	input := benchmarkPortfolio()
+	for b.Loop() {
+		closedFormStrategy(input, "avalanche", "")
+	}
+}
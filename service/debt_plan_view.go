@@ -0,0 +1,101 @@
+package service
+
+import "loan-agent/domain"
+
+var planViews = map[string]bool{
+	"":       true,
+	"full":   true,
+	"annual": true,
+	"page":   true,
+}
+
+func validatePlanView(input domain.DebtExitInput) error {
+	if !planViews[input.PlanView] {
+		return validationError("INVALID_PLAN_VIEW")
+	}
+	if input.Page < 0 {
+		return validationError("INVALID_PLAN_PAGE")
+	}
+	if input.PageSize < 0 || input.PageSize > MaxPlanPageSize {
+		return validationError("INVALID_PLAN_PAGE_SIZE", MaxPlanPageSize)
+	}
+	return nil
+}
+
+// applyPlanView recorta MonthlyPlan (y los planes de la comparación, si se
+// pidieron) según la vista pedida. Se aplica al final, cuando los totales,
+// resúmenes y explicaciones ya se calcularon con el plan completo.
+func applyPlanView(result *domain.DebtExitResult, input domain.DebtExitInput) {
+	result.MonthlyPlan, result.AnnualSummaries, result.Page = planView(result.MonthlyPlan, input)
+	if result.Comparison == nil {
+		return
+	}
+	for _, strategy := range []*domain.StrategyResult{
+		&result.Comparison.Snowball,
+		&result.Comparison.Avalanche,
+		&result.Comparison.CFI,
+	} {
+		if strategy.MonthlyPlan != nil {
+			strategy.MonthlyPlan, strategy.AnnualSummaries, strategy.Page = planView(strategy.MonthlyPlan, input)
+		}
+	}
+}
+
+func planView(
+	plan []domain.MonthlyPlan,
+	input domain.DebtExitInput,
+) ([]domain.MonthlyPlan, []domain.AnnualSummary, *domain.MonthlyPlanPage) {
+	switch input.PlanView {
+	case "annual":
+		return nil, annualSummaries(plan), nil
+	case "page":
+		pageSize := input.PageSize
+		if pageSize == 0 {
+			pageSize = DefaultPlanPageSize
+		}
+		page := max(input.Page, 1)
+		totalMonths := len(plan)
+
+		// Una página fuera de rango devuelve cero meses; TotalPages indica hasta dónde hay
+		from := min((page-1)*pageSize, totalMonths)
+		to := min(from+pageSize, totalMonths)
+		return plan[from:to], nil, &domain.MonthlyPlanPage{
+			Page:        page,
+			PageSize:    pageSize,
+			TotalMonths: totalMonths,
+			TotalPages:  (totalMonths + pageSize - 1) / pageSize,
+		}
+	}
+	return plan, nil, nil
+}
+
+// annualSummaries agrupa el plan en bloques de 12 meses contados desde el mes 1;
+// una deuda se liquida el mes en que su saldo restante llega a cero
+func annualSummaries(plan []domain.MonthlyPlan) []domain.AnnualSummary {
+	summaries := []domain.AnnualSummary{}
+	paidOff := make(map[string]bool)
+	for _, month := range plan {
+		year := (month.Month-1)/12 + 1
+		if len(summaries) < year {
+			summaries = append(summaries, domain.AnnualSummary{Year: year, FromDate: month.Date})
+		}
+		summary := &summaries[year-1]
+		summary.ToDate = month.Date
+		summary.TotalPaid += month.TotalPaid
+		summary.TotalInterest += month.TotalInterest
+		summary.TotalPrincipal += month.TotalPrincipal
+		for _, payment := range month.Payments {
+			if payment.RemainingBalance <= DebtBalanceTolerance && !paidOff[payment.DebtName] {
+				paidOff[payment.DebtName] = true
+				summary.DebtsPaidOff = append(summary.DebtsPaidOff, payment.DebtName)
+			}
+		}
+	}
+
+	for i := range summaries {
+		summaries[i].TotalPaid = roundTo2Decimals(summaries[i].TotalPaid)
+		summaries[i].TotalInterest = roundTo2Decimals(summaries[i].TotalInterest)
+		summaries[i].TotalPrincipal = roundTo2Decimals(summaries[i].TotalPrincipal)
+	}
+	return summaries
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"math"
+
+	"loan-agent/domain"
+)
+
+// avalancheWithQuickWin busca la desviación más barata de Avalanche que
+// liquide al menos una deuda en los primeros QuickWinMonths meses. Las
+// desviaciones candidatas adelantan una deuda al primer lugar y mantienen el
+// orden Avalanche para el resto.
+func (s *DebtExitService) avalancheWithQuickWin(input domain.DebtExitInput) domain.DebtExitResult {
+	avalanche := s.calculateStrategy(input, "avalanche")
+	quickWin := &domain.QuickWinResult{
+		Months:           input.QuickWinMonths,
+		FirstPayoffMonth: firstPayoffMonth(avalanche),
+	}
+
+	if quickWin.FirstPayoffMonth > 0 && quickWin.FirstPayoffMonth <= input.QuickWinMonths {
+		quickWin.Satisfied = true
+		avalanche.QuickWin = quickWin
+		return avalanche
+	}
+
+	var best *domain.DebtExitResult
+	for _, debt := range input.Debts {
+		candidate := s.strategyTotals(input, "avalanche", debt.Name)
+		payoff := firstPayoffMonth(candidate)
+		if payoff == 0 || payoff > input.QuickWinMonths {
+			continue
+		}
+		if best == nil || planCost(candidate) < planCost(*best) {
+			best = &candidate
+			quickWin.PrioritizedDebt = debt.Name
+			quickWin.FirstPayoffMonth = payoff
+		}
+	}
+
+	if best == nil {
+		avalanche.QuickWin = quickWin
+		return avalanche
+	}
+
+	// Los candidatos se comparan por sus totales; el elegido se simula mes a mes
+	plan := s.simulateStrategy(input, "avalanche", quickWin.PrioritizedDebt)
+	quickWin.Satisfied = true
+	quickWin.ExtraInterest = roundTo2Decimals(math.Max(0, plan.TotalInterestPaid-avalanche.TotalInterestPaid))
+	plan.QuickWin = quickWin
+	return plan
+}
+
+// firstPayoffMonth devuelve el primer mes en que se liquida alguna deuda, o 0 si ninguna
+func firstPayoffMonth(plan domain.DebtExitResult) int {
+	first := 0
+	for _, summary := range plan.DebtSummaries {
+		if summary.PayoffMonth > 0 && (first == 0 || summary.PayoffMonth < first) {
+			first = summary.PayoffMonth
+		}
+	}
+	return first
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"math"
+
+	"loan-agent/domain"
+)
+
+const targetPayoffSearchIterations = 60
+
+// SolveTargetPayoff busca, para cada estrategia, el pago mensual mínimo que
+// liquida todas las deudas en TargetMonths meses, usando búsqueda binaria
+// sobre el simulador
+func (s *DebtExitService) SolveTargetPayoff(
+	input domain.TargetPayoffInput,
+) (domain.TargetPayoffResult, error) {
+
+	if input.TargetMonths < 1 || input.TargetMonths > MaxDebtPayoffMonths {
+		return domain.TargetPayoffResult{}, validationError("INVALID_TARGET_MONTHS", MaxDebtPayoffMonths)
+	}
+	if input.PaymentGrowth != nil {
+		return domain.TargetPayoffResult{}, validationError("PAYMENT_GROWTH_WITH_TARGET")
+	}
+	if input.AvailableMonthlyPayment < 0 {
+		return domain.TargetPayoffResult{}, validationError("INVALID_AVAILABLE_PAYMENT")
+	}
+
+	debts, err := convertDebtsToUSD(input.Debts)
+	if err != nil {
+		return domain.TargetPayoffResult{}, err
+	}
+
+	// El menor presupuesto posible cubre los mínimos; el mayor paga todo en el
+	// primer mes en que existe cada deuda, con su interés
+	low := 0.0
+	high := 0.0
+	for _, debt := range debts {
+		low += debt.MinimumPayment
+		high += debt.Amount * (1 + debt.InterestRate/100/12)
+	}
+	high = math.Max(high, low)
+
+	// Validar el resto de la entrada con el presupuesto más alto
+	probe := input.DebtExitInput
+	probe.AvailableMonthlyPayment = high
+	probe.Strategy = "snowball"
+	probe.IncludeExplanation = withoutExplanation()
+	if _, err := s.CalculateDebtExitPlan(probe); err != nil {
+		return domain.TargetPayoffResult{}, err
+	}
+	probe.Debts = debts
+
+	results := []domain.TargetPayoffStrategyResult{}
+	for _, strategy := range []string{"snowball", "avalanche", "cfi"} {
+		results = append(results, s.solveStrategyBudget(probe, strategy, input.TargetMonths, low, high, input.AvailableMonthlyPayment))
+	}
+
+	return domain.TargetPayoffResult{
+		TargetMonths: input.TargetMonths,
+		Results:      results,
+	}, nil
+}
+
+func (s *DebtExitService) solveStrategyBudget(
+	input domain.DebtExitInput,
+	strategy string,
+	targetMonths int,
+	low, high, currentBudget float64,
+) domain.TargetPayoffStrategyResult {
+
+	simulate := func(budget float64) domain.DebtExitResult {
+		input.AvailableMonthlyPayment = budget
+		return s.strategyTotals(input, strategy, "")
+	}
+
+	best := simulate(high)
+	if best.MonthsToPayoff > targetMonths {
+		return domain.TargetPayoffStrategyResult{
+			Strategy:          strategy,
+			MonthsToPayoff:    best.MonthsToPayoff,
+			TotalInterestPaid: best.TotalInterestPaid,
+		}
+	}
+
+	if simulate(low).MonthsToPayoff <= targetMonths {
+		high = low
+	} else {
+		for i := 0; i < targetPayoffSearchIterations && high-low > 0.005; i++ {
+			mid := (low + high) / 2
+			if simulate(mid).MonthsToPayoff <= targetMonths {
+				high = mid
+			} else {
+				low = mid
+			}
+		}
+	}
+
+	// Redondear hacia arriba al centavo para garantizar el plazo
+	required := math.Ceil(high*100) / 100
+	best = simulate(required)
+
+	result := domain.TargetPayoffStrategyResult{
+		Strategy:               strategy,
+		Reachable:              true,
+		Feasible:               currentBudget == 0 || required <= currentBudget,
+		RequiredMonthlyPayment: required,
+		MonthsToPayoff:         best.MonthsToPayoff,
+		TotalInterestPaid:      best.TotalInterestPaid,
+	}
+	if currentBudget > 0 && required > currentBudget {
+		result.AdditionalPayment = roundTo2Decimals(required - currentBudget)
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"loan-agent/domain"
+)
+
+// RedactedValue reemplaza los valores secretos en la configuración efectiva
+const RedactedValue = "[REDACTED]"
+
+// configSetting describe una variable de entorno y cómo resolver su valor efectivo
+type configSetting struct {
+	name    string
+	secret  bool
+	resolve func() string
+}
+
+func configSettings() []configSetting {
+	formatFloat := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	rawEnv := func(name, fallback string) func() string {
+		return func() string {
+			if value := os.Getenv(name); value != "" {
+				return value
+			}
+			return fallback
+		}
+	}
+
+	settings := []configSetting{
+		{name: "MARKET_COUNTRY", resolve: func() string { return GetMarket().Country }},
+		{name: "MARKET_USD_RATE", resolve: func() string { return formatFloat(GetMarketUSDRate()) }},
+		{name: "USD_TO_NIO_RATE", resolve: func() string { return formatFloat(GetUSDToNIORate()) }},
+		{name: "NIO_ANNUAL_DEVALUATION", resolve: func() string { return formatFloat(GetNIOAnnualDevaluation()) }},
+		{name: "LTV_ENFORCEMENT", resolve: GetLTVEnforcement},
+		{name: "MAX_DTI", resolve: func() string { return formatFloat(GetMaxDebtToIncome()) }},
+		{name: "SLO_P95_LATENCY_MS", resolve: func() string { return GetSLOLatencyBudget().String() }},
+		{name: "SLO_MAX_ERROR_RATE", resolve: func() string { return formatFloat(GetSLOMaxErrorRate()) }},
+		{name: "ALERT_WEBHOOK_URL", secret: true, resolve: GetAlertWebhookURL},
+		{name: "DRAIN_PERIOD_SECONDS", resolve: func() string { return GetDrainPeriod().String() }},
+		{name: "LOAN_SNAPSHOT_PATH", resolve: GetLoanSnapshotPath},
+		{name: "LOAN_SNAPSHOT_INTERVAL_SECONDS", resolve: func() string { return GetLoanSnapshotInterval().String() }},
+		{name: "PROMETHEUS_REMOTE_WRITE_URL", secret: true, resolve: GetPrometheusRemoteWriteURL},
+		{name: "DUPLICATE_REQUEST_WINDOW_SECONDS", resolve: func() string { return GetDuplicateRequestWindow().String() }},
+		{name: "RATE_LIMIT_CAPACITY", resolve: func() string { return strconv.Itoa(GetRateLimitCapacity("")) }},
+		{name: "RATE_LIMIT_WINDOW_SECONDS", resolve: func() string { return GetRateLimitWindow("").String() }},
+		{name: "RATE_LIMIT_CLEANUP_INTERVAL_SECONDS", resolve: func() string { return GetRateLimitCleanupInterval().String() }},
+		{name: "RATE_LIMIT_BUCKET_TTL_SECONDS", resolve: func() string { return GetRateLimitBucketTTL().String() }},
+		{name: "IDEMPOTENCY_KEY_TTL_SECONDS", resolve: func() string { return GetIdempotencyKeyTTL().String() }},
+		{name: "MAX_REQUEST_BODY_BYTES", resolve: func() string { return strconv.FormatInt(GetMaxRequestBodyBytes(), 10) }},
+		{name: "JWT_ISSUER", resolve: GetJWTIssuer},
+		{name: "JWT_AUDIENCE", resolve: GetJWTAudience},
+		{name: "JWT_JWKS_URL", resolve: GetJWKSURL},
+		{name: "SANDBOX_MODE", resolve: func() string { return strconv.FormatBool(GetSandboxMode()) }},
+		{name: "SECRETS_PROVIDER", resolve: rawEnv("SECRETS_PROVIDER", "env")},
+		{name: "SECRETS_DIR", resolve: rawEnv("SECRETS_DIR", "/run/secrets")},
+		{name: "SECRETS_CACHE_TTL", resolve: rawEnv("SECRETS_CACHE_TTL", "")},
+		{name: "VAULT_ADDR", resolve: rawEnv("VAULT_ADDR", "")},
+		{name: "VAULT_KV_MOUNT", resolve: rawEnv("VAULT_KV_MOUNT", "")},
+		{name: "VAULT_SECRET_PATH", resolve: rawEnv("VAULT_SECRET_PATH", "")},
+		{name: "VAULT_TOKEN", secret: true, resolve: rawEnv("VAULT_TOKEN", "")},
+	}
+
+	for _, stage := range HookStages {
+		settings = append(settings, configSetting{
+			name:    "HOOK_" + strings.ToUpper(string(stage)) + "_URL",
+			secret:  true,
+			resolve: func() string { return GetHookWebhookURL(stage) },
+		})
+	}
+
+	for _, group := range RateLimitRouteGroups {
+		settings = append(settings,
+			configSetting{
+				name:    rateLimitEnvName(group, "CAPACITY"),
+				resolve: func() string { return strconv.Itoa(GetRateLimitCapacity(group)) },
+			},
+			configSetting{
+				name:    rateLimitEnvName(group, "WINDOW_SECONDS"),
+				resolve: func() string { return GetRateLimitWindow(group).String() },
+			},
+		)
+	}
+
+	collateralTypes := make([]string, 0, len(defaultMaxLTV))
+	for collateralType := range defaultMaxLTV {
+		collateralTypes = append(collateralTypes, collateralType)
+	}
+	sort.Strings(collateralTypes)
+	for _, collateralType := range collateralTypes {
+		settings = append(settings, configSetting{
+			name: "MAX_LTV_" + strings.ToUpper(collateralType),
+			resolve: func() string {
+				ltv, _ := GetMaxLTV(collateralType)
+				return formatFloat(ltv)
+			},
+		})
+	}
+
+	roles := make([]string, 0, len(defaultBorrowerIncomeWeight))
+	for role := range defaultBorrowerIncomeWeight {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		settings = append(settings, configSetting{
+			name: "INCOME_WEIGHT_" + strings.ToUpper(role),
+			resolve: func() string {
+				weight, _ := GetBorrowerIncomeWeight(role)
+				return formatFloat(weight)
+			},
+		})
+	}
+
+	return settings
+}
+
+// EffectiveConfig devuelve la configuración resuelta por variables de entorno,
+// indicando si cada valor viene del entorno o es el predeterminado. Los valores
+// secretos se ocultan.
+func EffectiveConfig() []domain.ConfigEntry {
+	settings := configSettings()
+	entries := make([]domain.ConfigEntry, 0, len(settings))
+
+	for _, setting := range settings {
+		source := "default"
+		if os.Getenv(setting.name) != "" {
+			source = "env"
+		}
+
+		value := setting.resolve()
+		if setting.secret && value != "" {
+			value = RedactedValue
+		}
+
+		entries = append(entries, domain.ConfigEntry{
+			Name:   setting.name,
+			Value:  value,
+			Source: source,
+			Secret: setting.secret,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,238 @@
+package service
+
+// errorMessages contiene los mensajes de los errores de validación por idioma,
+// indexados por código; las claves en minúscula son el contexto que agrega
+// wrapError, con el mensaje del error envuelto como primer argumento, y los
+// textos de sugerencias ("suggestion.") y advertencias ("warning.") de los
+// resultados. Cada idioma debe definir las mismas claves con los mismos verbos
+// de formato.
+var errorMessages = map[string]map[string]string{
+	"es": {
+		"offer.balance_transfer":         "oferta de traslado inválida: %s",
+		"offer.consolidation":            "oferta de consolidación inválida: %s",
+		"term.lowest_payment":            "%s; la cuota más baja posible es %s a %d meses",
+		"with_suggestions":               "%s. %s",
+		"suggestion.amount":              "¿Quisiste decir $%.2f? $%.2f a %d meses es un monto inusualmente bajo",
+		"suggestion.rate":                "¿La tasa de %.2f%% es mensual? La tasa es anual; equivale a %.2f%% anual",
+		"suggestion.term":                "¿Quisiste decir %d meses? Un plazo de %d meses parece estar en años",
+		"warning.ltv_exceeded":           "El LTV de %.2f%% excede el máximo de %.2f%% para la garantía; monto máximo financiable: $%.2f",
+		"AMOUNT_TOO_LARGE":               "monto excede el máximo permitido de $%.2f",
+		"BALANCE_TOO_LARGE":              "saldo excede el máximo permitido de $%.2f",
+		"DATE_RANGE_TOO_LONG":            "rango de fechas excede el máximo de %d días",
+		"DEBT_AMOUNT_TOO_LARGE":          "monto de deuda excede el máximo de $%.2f",
+		"DEBT_INTEREST_RATE_TOO_HIGH":    "tasa de interés excede el máximo de %.2f%%",
+		"DUPLICATE_DEBT_NAME":            "nombre de deuda duplicado: %s",
+		"DUPLICATE_RATE_TIER":            "tramos de tasa con el mismo plazo máximo",
+		"EMPTY_DEBT_NAME":                "nombre de deuda no puede estar vacío",
+		"EMPTY_TAG":                      "tag no puede estar vacío",
+		"INSUFFICIENT_AVAILABLE_PAYMENT": "el pago mensual disponible es insuficiente para cubrir los pagos mínimos",
+		"INSURANCE_RATE_TOO_HIGH":        "tasa de seguro excede el máximo permitido de %.2f%% mensual",
+		"INTEREST_RATE_TOO_HIGH":         "tasa de interés excede el máximo permitido de %.2f%%",
+		"INVALID_ACCRUAL_DAYS":           "días desde el último pago deben estar entre 1 y %d",
+		"INVALID_AMOUNT":                 "monto inválido",
+		"INVALID_APPRAISED_VALUE":        "valor de avalúo inválido",
+		"INVALID_AVAILABLE_PAYMENT":      "pago mensual disponible inválido",
+		"INVALID_BALANCE":                "saldo inválido",
+		"INVALID_BORROWER_ROLE":          "rol de deudor inválido",
+		"INVALID_CLOSING_COSTS":          "gastos de cierre inválidos",
+		"INVALID_COLLATERAL_TYPE":        "tipo de garantía inválido",
+		"INVALID_COMPOUNDING":            "convención de capitalización inválida para %s",
+		"INVALID_CURRENCY":               "moneda inválida para %s",
+		"INVALID_DATE_RANGE":             "rango de fechas inválido",
+		"INVALID_DAY_COUNT_CONVENTION":   "convención de devengo inválida",
+		"INVALID_DEBT_AMOUNT":            "monto de deuda inválido",
+		"INVALID_DEBT_INTEREST_RATE":     "tasa de interés inválida",
+		"INVALID_DEBT_START_MONTH":       "mes de inicio inválido para %s",
+		"INVALID_FEE_AMOUNT":             "monto de cargo inválido para %s",
+		"INVALID_FEE_FREQUENCY":          "periodicidad de cargo inválida para %s",
+		"INVALID_FEE_START_MONTH":        "mes del primer cargo inválido para %s",
+		"INVALID_HARDSHIP_MODE":          "modo de mes de dificultad inválido",
+		"INVALID_HARDSHIP_MONTH":         "mes de dificultad inválido: %d",
+		"INVALID_INSURANCE_TYPE":         "tipo de seguro inválido",
+		"INVALID_INSURANCE_VALUE":        "valor de seguro inválido",
+		"INVALID_INTEREST_RATE":          "tasa inválida",
+		"INVALID_INTERVAL":               "intervalo inválido",
+		"INVALID_LUMP_SUM_AMOUNT":        "monto de pago extra inválido",
+		"INVALID_LUMP_SUM_MONTH":         "mes de pago extra inválido: %d",
+		"INVALID_MAX_MONTHLY_PAYMENT":    "pago mensual máximo inválido",
+		"INVALID_MAX_PAYMENT_RATIO":      "relación cuota/ingreso máxima debe estar entre 0%% y 100%%",
+		"INVALID_MINIMUM_PAYMENT":        "pago mínimo inválido",
+		"INVALID_MONTHLY_INCOME":         "ingreso mensual inválido",
+		"INVALID_OBLIGATION_PAYMENT":     "pago de obligación inválido",
+		"INVALID_ORIGINATION_FEE":        "comisión de apertura debe estar entre 0%% y %.2f%%",
+		"INVALID_OUTSTANDING_BALANCE":    "saldo pendiente inválido",
+		"INVALID_PAYMENT":                "pago inválido",
+		"INVALID_PAYMENT_GROWTH":         "crecimiento anual del pago debe estar entre 0%% y %.2f%%",
+		"INVALID_PAYMENT_STEP_MONTH":     "mes de escalón de pago inválido: %d",
+		"INVALID_PENDING_FEES":           "comisiones pendientes inválidas",
+		"INVALID_PLAN_PAGE":              "página del plan inválida",
+		"INVALID_PLAN_PAGE_SIZE":         "tamaño de página debe estar entre 1 y %d meses",
+		"INVALID_PLAN_VIEW":              "vista del plan inválida",
+		"INVALID_PREFERENCE":             "preferencia inválida",
+		"INVALID_PROMO_MONTHS":           "meses de promoción inválidos para %s",
+		"INVALID_PROMO_RATE":             "tasa promocional inválida para %s",
+		"INVALID_QUICK_WIN_MONTHS":       "plazo de victoria rápida inválido",
+		"INVALID_RATE_TIER_RATE":         "tasa inválida en el tramo de hasta %d meses",
+		"INVALID_RATE_TIER_TERM":         "plazo de tramo inválido: %d meses",
+		"INVALID_READING_LEVEL":          "nivel de lectura inválido",
+		"INVALID_REMAINING_TERM":         "plazo restante debe estar entre %d y %d meses",
+		"INVALID_ROUNDING_UNIT":          "unidad de redondeo debe estar entre 0 y %.2f",
+		"INVALID_ROUND_UP_MULTIPLIER":    "multiplicador de redondeo debe estar entre 0 y %.0f",
+		"INVALID_ROUND_UP_TRANSACTIONS":  "transacciones por mes deben estar entre 1 y %d",
+		"INVALID_SCORING_WEIGHTS_SUM":    "los pesos de puntuación deben sumar 1",
+		"INVALID_SNOWFLAKE_AMOUNT":       "monto de snowflake inválido",
+		"INVALID_SNOWFLAKE_MONTH":        "mes de snowflake inválido: %d",
+		"INVALID_START_DATE":             "fecha de inicio inválida, use el formato AAAA-MM",
+		"INVALID_STRATEGY":               "estrategia inválida",
+		"INVALID_STRATEGY_WEIGHTS":       "pesos de la estrategia inválidos",
+		"INVALID_TARGET_MONTHS":          "plazo objetivo debe estar entre 1 y %d meses",
+		"INVALID_TERM":                   "plazo inválido",
+		"INVALID_TERM_RANGE":             "plazos inválidos",
+		"INVALID_TERM_STEP":              "incremento de plazos inválido",
+		"INVALID_TRANSFER_FEE":           "comisión de traslado debe estar entre 0%% y %.2f%%",
+		"LTV_EXCEEDED":                   "monto excede el LTV máximo de %.2f%% para la garantía; monto máximo financiable: $%.2f",
+		"MAX_TERM_TOO_LONG":              "plazo máximo excede el límite de %d meses",
+		"MINIMUM_PAYMENT_BELOW_INTEREST": "pago mínimo de %s ($%.2f) es menor que el interés mensual ($%.2f)",
+		"MIN_TERM_ABOVE_MAX":             "plazo mínimo mayor que máximo",
+		"NEGATIVE_SCORING_WEIGHTS":       "los pesos de puntuación no pueden ser negativos",
+		"NO_AFFORDABLE_TERM":             "no se encontraron plazos válidos con el pago mensual máximo especificado",
+		"NO_DEBTS":                       "no se proporcionaron deudas",
+		"NO_PAYMENT_CAPACITY":            "las obligaciones existentes agotan la capacidad de pago",
+		"NO_TRANSFER_DEBTS":              "no se indicaron deudas a trasladar",
+		"PAYMENT_GROWTH_WITH_TARGET":     "el crecimiento del pago no es compatible con un plazo objetivo",
+		"PAYMENT_STEP_BELOW_INITIAL":     "los escalones de pago no pueden ser menores al pago mensual disponible inicial",
+		"PRIMARY_BORROWER_REQUIRED":      "debe haber exactamente un deudor principal",
+		"QUICK_WIN_REQUIRES_AVALANCHE":   "la victoria rápida solo aplica a la estrategia avalanche",
+		"RATE_TIERS_MISS_MIN_TERM":       "ningún tramo de tasa cubre el plazo mínimo",
+		"STRATEGY_WEIGHTS_REQUIRED":      "la estrategia ponderada requiere StrategyWeights",
+		"TAG_TOO_LONG":                   "tag excede el máximo de %d caracteres",
+		"TERM_RANGE_TOO_LARGE":           "rango de plazos excede el máximo de %d plazos",
+		"TERM_TOO_LONG":                  "plazo excede el máximo permitido de %d meses",
+		"TOO_MANY_BORROWERS":             "número de deudores excede el máximo de %d",
+		"TOO_MANY_DEBTS":                 "número de deudas excede el máximo de %d",
+		"TOO_MANY_DEBT_FEES":             "número de cargos de %s excede el máximo de %d",
+		"TOO_MANY_HARDSHIP_MONTHS":       "número de meses de dificultad excede el máximo de %d",
+		"TOO_MANY_LUMP_SUMS":             "número de pagos extra excede el máximo de %d",
+		"TOO_MANY_PAYMENT_STEPS":         "número de escalones de pago excede el máximo de %d",
+		"TOO_MANY_RATE_TIERS":            "máximo %d tramos de tasa por request",
+		"TOO_MANY_SNOWFLAKES":            "número de snowflakes excede el máximo de %d",
+		"TOO_MANY_TAGS":                  "número de tags excede el máximo de %d",
+		"UNKNOWN_SNOWFLAKE_DEBT":         "snowflake para deuda inexistente: %s",
+		"UNKNOWN_TRANSFER_DEBT":          "alguna deuda a trasladar no existe en el portafolio",
+		"UNSUPPORTED_LANGUAGE":           "idioma no soportado: %q",
+	},
+	"en": {
+		"offer.balance_transfer":         "invalid balance transfer offer: %s",
+		"offer.consolidation":            "invalid consolidation offer: %s",
+		"term.lowest_payment":            "%s; the lowest possible payment is %s over %d months",
+		"with_suggestions":               "%s. %s",
+		"suggestion.amount":              "Did you mean $%.2f? $%.2f over %d months is an unusually low amount",
+		"suggestion.rate":                "Is the %.2f%% rate monthly? The rate is annual; it is equivalent to %.2f%% per year",
+		"suggestion.term":                "Did you mean %d months? A term of %d months looks like it is in years",
+		"warning.ltv_exceeded":           "The LTV of %.2f%% exceeds the maximum of %.2f%% for the collateral; maximum financeable amount: $%.2f",
+		"AMOUNT_TOO_LARGE":               "amount exceeds the maximum allowed of $%.2f",
+		"BALANCE_TOO_LARGE":              "balance exceeds the maximum allowed of $%.2f",
+		"DATE_RANGE_TOO_LONG":            "date range exceeds the maximum of %d days",
+		"DEBT_AMOUNT_TOO_LARGE":          "debt amount exceeds the maximum of $%.2f",
+		"DEBT_INTEREST_RATE_TOO_HIGH":    "interest rate exceeds the maximum of %.2f%%",
+		"DUPLICATE_DEBT_NAME":            "duplicate debt name: %s",
+		"DUPLICATE_RATE_TIER":            "rate tiers with the same maximum term",
+		"EMPTY_DEBT_NAME":                "debt name cannot be empty",
+		"EMPTY_TAG":                      "tag cannot be empty",
+		"INSUFFICIENT_AVAILABLE_PAYMENT": "available monthly payment does not cover the minimum payments",
+		"INSURANCE_RATE_TOO_HIGH":        "insurance rate exceeds the maximum allowed of %.2f%% per month",
+		"INTEREST_RATE_TOO_HIGH":         "interest rate exceeds the maximum allowed of %.2f%%",
+		"INVALID_ACCRUAL_DAYS":           "days since the last payment must be between 1 and %d",
+		"INVALID_AMOUNT":                 "invalid amount",
+		"INVALID_APPRAISED_VALUE":        "invalid appraised value",
+		"INVALID_AVAILABLE_PAYMENT":      "invalid available monthly payment",
+		"INVALID_BALANCE":                "invalid balance",
+		"INVALID_BORROWER_ROLE":          "invalid borrower role",
+		"INVALID_CLOSING_COSTS":          "invalid closing costs",
+		"INVALID_COLLATERAL_TYPE":        "invalid collateral type",
+		"INVALID_COMPOUNDING":            "invalid compounding convention for %s",
+		"INVALID_CURRENCY":               "invalid currency for %s",
+		"INVALID_DATE_RANGE":             "invalid date range",
+		"INVALID_DAY_COUNT_CONVENTION":   "invalid accrual convention",
+		"INVALID_DEBT_AMOUNT":            "invalid debt amount",
+		"INVALID_DEBT_INTEREST_RATE":     "invalid interest rate",
+		"INVALID_DEBT_START_MONTH":       "invalid start month for %s",
+		"INVALID_FEE_AMOUNT":             "invalid fee amount for %s",
+		"INVALID_FEE_FREQUENCY":          "invalid fee frequency for %s",
+		"INVALID_FEE_START_MONTH":        "invalid first fee month for %s",
+		"INVALID_HARDSHIP_MODE":          "invalid hardship month mode",
+		"INVALID_HARDSHIP_MONTH":         "invalid hardship month: %d",
+		"INVALID_INSURANCE_TYPE":         "invalid insurance type",
+		"INVALID_INSURANCE_VALUE":        "invalid insurance value",
+		"INVALID_INTEREST_RATE":          "invalid interest rate",
+		"INVALID_INTERVAL":               "invalid interval",
+		"INVALID_LUMP_SUM_AMOUNT":        "invalid lump sum amount",
+		"INVALID_LUMP_SUM_MONTH":         "invalid lump sum month: %d",
+		"INVALID_MAX_MONTHLY_PAYMENT":    "invalid maximum monthly payment",
+		"INVALID_MAX_PAYMENT_RATIO":      "maximum payment-to-income ratio must be between 0%% and 100%%",
+		"INVALID_MINIMUM_PAYMENT":        "invalid minimum payment",
+		"INVALID_MONTHLY_INCOME":         "invalid monthly income",
+		"INVALID_OBLIGATION_PAYMENT":     "invalid obligation payment",
+		"INVALID_ORIGINATION_FEE":        "origination fee must be between 0%% and %.2f%%",
+		"INVALID_OUTSTANDING_BALANCE":    "invalid outstanding balance",
+		"INVALID_PAYMENT":                "invalid payment",
+		"INVALID_PAYMENT_GROWTH":         "annual payment growth must be between 0%% and %.2f%%",
+		"INVALID_PAYMENT_STEP_MONTH":     "invalid payment step month: %d",
+		"INVALID_PENDING_FEES":           "invalid pending fees",
+		"INVALID_PLAN_PAGE":              "invalid plan page",
+		"INVALID_PLAN_PAGE_SIZE":         "page size must be between 1 and %d months",
+		"INVALID_PLAN_VIEW":              "invalid plan view",
+		"INVALID_PREFERENCE":             "invalid preference",
+		"INVALID_PROMO_MONTHS":           "invalid promotional months for %s",
+		"INVALID_PROMO_RATE":             "invalid promotional rate for %s",
+		"INVALID_QUICK_WIN_MONTHS":       "invalid quick win term",
+		"INVALID_RATE_TIER_RATE":         "invalid rate in the tier up to %d months",
+		"INVALID_RATE_TIER_TERM":         "invalid tier term: %d months",
+		"INVALID_READING_LEVEL":          "invalid reading level",
+		"INVALID_REMAINING_TERM":         "remaining term must be between %d and %d months",
+		"INVALID_ROUNDING_UNIT":          "rounding unit must be between 0 and %.2f",
+		"INVALID_ROUND_UP_MULTIPLIER":    "round-up multiplier must be between 0 and %.0f",
+		"INVALID_ROUND_UP_TRANSACTIONS":  "transactions per month must be between 1 and %d",
+		"INVALID_SCORING_WEIGHTS_SUM":    "scoring weights must add up to 1",
+		"INVALID_SNOWFLAKE_AMOUNT":       "invalid snowflake amount",
+		"INVALID_SNOWFLAKE_MONTH":        "invalid snowflake month: %d",
+		"INVALID_START_DATE":             "invalid start date, use the YYYY-MM format",
+		"INVALID_STRATEGY":               "invalid strategy",
+		"INVALID_STRATEGY_WEIGHTS":       "invalid strategy weights",
+		"INVALID_TARGET_MONTHS":          "target term must be between 1 and %d months",
+		"INVALID_TERM":                   "invalid term",
+		"INVALID_TERM_RANGE":             "invalid terms",
+		"INVALID_TERM_STEP":              "invalid term step",
+		"INVALID_TRANSFER_FEE":           "transfer fee must be between 0%% and %.2f%%",
+		"LTV_EXCEEDED":                   "amount exceeds the maximum LTV of %.2f%% for the collateral; maximum financeable amount: $%.2f",
+		"MAX_TERM_TOO_LONG":              "maximum term exceeds the limit of %d months",
+		"MINIMUM_PAYMENT_BELOW_INTEREST": "minimum payment of %s ($%.2f) is less than the monthly interest ($%.2f)",
+		"MIN_TERM_ABOVE_MAX":             "minimum term is greater than maximum term",
+		"NEGATIVE_SCORING_WEIGHTS":       "scoring weights cannot be negative",
+		"NO_AFFORDABLE_TERM":             "no valid terms found for the specified maximum monthly payment",
+		"NO_DEBTS":                       "no debts were provided",
+		"NO_PAYMENT_CAPACITY":            "existing obligations exhaust the payment capacity",
+		"NO_TRANSFER_DEBTS":              "no debts to transfer were specified",
+		"PAYMENT_GROWTH_WITH_TARGET":     "payment growth is not compatible with a target term",
+		"PAYMENT_STEP_BELOW_INITIAL":     "payment steps cannot be lower than the initial available monthly payment",
+		"PRIMARY_BORROWER_REQUIRED":      "there must be exactly one primary borrower",
+		"QUICK_WIN_REQUIRES_AVALANCHE":   "quick win only applies to the avalanche strategy",
+		"RATE_TIERS_MISS_MIN_TERM":       "no rate tier covers the minimum term",
+		"STRATEGY_WEIGHTS_REQUIRED":      "the weighted strategy requires StrategyWeights",
+		"TAG_TOO_LONG":                   "tag exceeds the maximum of %d characters",
+		"TERM_RANGE_TOO_LARGE":           "term range exceeds the maximum of %d terms",
+		"TERM_TOO_LONG":                  "term exceeds the maximum allowed of %d months",
+		"TOO_MANY_BORROWERS":             "number of borrowers exceeds the maximum of %d",
+		"TOO_MANY_DEBTS":                 "number of debts exceeds the maximum of %d",
+		"TOO_MANY_DEBT_FEES":             "number of fees for %s exceeds the maximum of %d",
+		"TOO_MANY_HARDSHIP_MONTHS":       "number of hardship months exceeds the maximum of %d",
+		"TOO_MANY_LUMP_SUMS":             "number of lump sums exceeds the maximum of %d",
+		"TOO_MANY_PAYMENT_STEPS":         "number of payment steps exceeds the maximum of %d",
+		"TOO_MANY_RATE_TIERS":            "maximum %d rate tiers per request",
+		"TOO_MANY_SNOWFLAKES":            "number of snowflakes exceeds the maximum of %d",
+		"TOO_MANY_TAGS":                  "number of tags exceeds the maximum of %d",
+		"UNKNOWN_SNOWFLAKE_DEBT":         "snowflake for a nonexistent debt: %s",
+		"UNKNOWN_TRANSFER_DEBT":          "some debt to transfer is not in the portfolio",
+		"UNSUPPORTED_LANGUAGE":           "unsupported language: %q",
+	},
+}
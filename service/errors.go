@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// localizedError es un error cuyo mensaje sale del catálogo errorMessages
+type localizedError interface {
+	error
+	localize(lang string) string
+}
+
+// ValidationError es un error en los datos de entrada con un código estable
+// (ej. "INVALID_TERM_RANGE") para que los clientes no dependan del texto; el
+// mensaje se arma con el código y Args en el idioma de la respuesta
+type ValidationError struct {
+	Code string
+	Args []any
+}
+
+func (e *ValidationError) Error() string {
+	return e.localize(DefaultLanguage)
+}
+
+func (e *ValidationError) localize(lang string) string {
+	return errorText(lang, e.Code, e.Args...)
+}
+
+func validationError(code string, args ...any) *ValidationError {
+	return &ValidationError{Code: code, Args: args}
+}
+
+// wrappedError agrega a err el contexto de la clave key del catálogo
+type wrappedError struct {
+	key  string
+	args []any
+	err  error
+}
+
+// wrapError envuelve err con el mensaje key del catálogo; el mensaje de err es
+// el primer argumento de la plantilla y args los siguientes
+func wrapError(err error, key string, args ...any) error {
+	return &wrappedError{key: key, args: args, err: err}
+}
+
+func (e *wrappedError) Error() string {
+	return e.localize(DefaultLanguage)
+}
+
+func (e *wrappedError) localize(lang string) string {
+	return errorText(lang, e.key, append([]any{LocalizedMessage(e.err, lang)}, e.args...)...)
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.err
+}
+
+// errorText formatea la plantilla key en el idioma pedido, o en el idioma por
+// defecto si el idioma no está en el catálogo
+func errorText(lang, key string, args ...any) string {
+	messages, ok := errorMessages[lang]
+	if !ok {
+		messages = errorMessages[DefaultLanguage]
+	}
+	template, ok := messages[key]
+	if !ok {
+		return key
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// LocalizedMessage devuelve el mensaje de err para el usuario en el idioma
+// pedido; los errores que no son de validación conservan su texto
+func LocalizedMessage(err error, lang string) string {
+	var localized localizedError
+	if errors.As(err, &localized) {
+		return localized.localize(lang)
+	}
+	return err.Error()
+}
+
+// FieldError es el error de validación de un campo de la entrada; Field es la
+// ruta del campo (ej. "Debts[2].Amount")
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	err error
+}
+
+// ValidationErrors reúne todos los campos inválidos de una entrada para
+// reportarlos en una sola respuesta
+type ValidationErrors struct {
+	Fields []FieldError
+}
+
+func (e *ValidationErrors) Error() string {
+	return e.localize(DefaultLanguage)
+}
+
+func (e *ValidationErrors) localize(lang string) string {
+	fields := e.LocalizedFields(lang)
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// LocalizedFields devuelve los campos inválidos con el mensaje en el idioma pedido
+func (e *ValidationErrors) LocalizedFields(lang string) []FieldError {
+	fields := make([]FieldError, len(e.Fields))
+	for i, field := range e.Fields {
+		if field.err != nil {
+			field.Message = LocalizedMessage(field.err, lang)
+		}
+		fields[i] = field
+	}
+	return fields
+}
+
+// fieldValidator acumula los errores por campo en vez de cortar en el primero
+type fieldValidator struct {
+	fields []FieldError
+}
+
+func (v *fieldValidator) add(field string, err error) {
+	code := ErrorCode(err)
+	if code == "" {
+		code = "INVALID_INPUT"
+	}
+	v.fields = append(v.fields, FieldError{Field: field, Code: code, Message: err.Error(), err: err})
+}
+
+// check registra err para el campo si no es nil
+func (v *fieldValidator) check(field string, err error) {
+	if err != nil {
+		v.add(field, err)
+	}
+}
+
+// err devuelve los errores acumulados como *ValidationErrors, o nil si no hay
+func (v *fieldValidator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationErrors{Fields: v.fields}
+}
+
+// prefixFields antepone prefix a los campos de err cuando es un
+// *ValidationErrors de una entrada anidada (ej. la oferta de consolidación)
+func prefixFields(err error, prefix string) error {
+	var validation *ValidationErrors
+	if !errors.As(err, &validation) {
+		return err
+	}
+	fields := make([]FieldError, len(validation.Fields))
+	for i, field := range validation.Fields {
+		field.Field = prefix + field.Field
+		fields[i] = field
+	}
+	return &ValidationErrors{Fields: fields}
+}
+
+// ErrorCode devuelve el código del primer error de validación en la cadena de
+// err, o "" si err no es un error de validación. Los errores por campo se
+// reportan como "VALIDATION_FAILED"; cada campo trae su propio código.
+func ErrorCode(err error) string {
+	var validation *ValidationError
+	if errors.As(err, &validation) {
+		return validation.Code
+	}
+	var fields *ValidationErrors
+	if errors.As(err, &fields) {
+		return "VALIDATION_FAILED"
+	}
+	return ""
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+)
+
+// DefaultLanguage es el idioma de las explicaciones cuando no se indica otro
+const DefaultLanguage = "es"
+
+// SupportedLanguages lista los idiomas con plantillas de explicación
+var SupportedLanguages = []string{"es", "en"}
+
+// SupportsLanguage indica si hay plantillas de explicación para el idioma
+func SupportsLanguage(lang string) bool {
+	return slices.Contains(SupportedLanguages, lang)
+}
+
+// explanationRequested indica si la solicitud pide explicación: sí, salvo que
+// IncludeExplanation sea false
+func explanationRequested(include *bool) bool {
+	return include == nil || *include
+}
+
+// withoutExplanation es IncludeExplanation=false, para los cálculos internos
+// que solo usan los resultados numéricos
+func withoutExplanation() *bool {
+	include := false
+	return &include
+}
+
+// validateLanguage valida el idioma pedido y lo resuelve; vacío es el idioma por defecto
+func validateLanguage(lang string) (string, error) {
+	if lang == "" {
+		return DefaultLanguage, nil
+	}
+	if !SupportsLanguage(lang) {
+		return "", validationError("UNSUPPORTED_LANGUAGE", lang)
+	}
+	return lang, nil
+}
+
+// explanationMessages contiene las plantillas de las explicaciones por idioma.
+// Cada idioma debe definir las mismas claves con los mismos verbos de formato.
+var explanationMessages = map[string]map[string]string{
+	"es": {
+		"debt.strategy.snowball":      "Snowball (Bola de Nieve)",
+		"debt.strategy.avalanche":     "Avalanche (Avalancha)",
+		"debt.tip.snowball":           "Ideal si necesitas ver resultados rápidos para mantenerte motivado. Cada deuda pagada libera capital que puedes aplicar a la siguiente.",
+		"debt.tip.avalanche":          "Ideal si tu objetivo principal es minimizar el costo financiero total. Requiere disciplina pero maximiza el ahorro.",
+		"debt.strategy.cfi":           "Flujo de Caja (CFI)",
+		"debt.tip.cfi":                "Ideal si necesitas liberar flujo de caja mensual cuanto antes. Cada deuda liquidada elimina el pago mínimo más alto en relación con su saldo.",
+		"debt.tip.cfi.basic":          "Pagas primero la deuda con el pago mínimo más alto para lo que debes. Así te queda más dinero libre cada mes.",
+		"debt.tip.cfi.advanced":       "Prioriza el menor índice saldo/pago mínimo, maximizando el flujo de caja liberado por dólar abonado; el interés total suele quedar entre Snowball y Avalanche.",
+		"debt.vs_cfi":                 "\n\nLa estrategia Flujo de Caja (CFI) liquidaría tus deudas en %d meses con %s en intereses.",
+		"debt.cfi_vs_others":          "\n\nComparado con Snowball (%d meses, %s en intereses) y Avalanche (%d meses, %s en intereses), Flujo de Caja es la opción de menor costo para tus deudas.",
+		"debt.strategy.weighted":      "Ponderada (tasa y saldo)",
+		"debt.tip.weighted":           "Ideal si quieres un punto medio entre Snowball y Avalanche: ajusta los pesos hacia la tasa para ahorrar intereses o hacia el saldo para ver deudas liquidadas antes.",
+		"debt.tip.weighted.basic":     "Pagas primero la deuda que combina más interés y menos saldo. Puedes cambiar los pesos si prefieres ahorrar más o avanzar más rápido.",
+		"debt.tip.weighted.advanced":  "Ordena por una combinación lineal de la tasa anual y el saldo normalizados; con peso 1 en la tasa equivale a Avalanche y con peso 1 en el saldo a Snowball.",
+		"debt.weights":                "Cada deuda se prioriza con un %.0f%% de peso para la tasa de interés y un %.0f%% para el saldo. ",
+		"debt.summary":                "La estrategia %s te permitirá liquidar todas tus deudas en %d meses (%.1f años). ",
+		"debt.fees":                   "El costo total incluye %s en cargos periódicos (anualidades y mantenimiento). ",
+		"debt.cost":                   "Tu deuda inicial es %s y pagarás %s en intereses, para un costo total de %s. ",
+		"debt.health.low":             "Tu tasa promedio ponderada es %.2f%% anual y el %.2f%% de tu pago del primer mes se va en intereses, un nivel de riesgo bajo. ",
+		"debt.health.medium":          "Tu tasa promedio ponderada es %.2f%% anual y el %.2f%% de tu pago del primer mes se va en intereses, un nivel de riesgo moderado. ",
+		"debt.health.high":            "Tu tasa promedio ponderada es %.2f%% anual y el %.2f%% de tu pago del primer mes se va en intereses, un nivel de riesgo alto: conviene priorizar las deudas más caras y evitar nuevo endeudamiento. ",
+		"debt.health.dti":             "Tus pagos mínimos representan el %.2f%% de tu ingreso mensual. ",
+		"debt.order":                  "\n\nCon %s, el orden de pago es:\n",
+		"debt.order.nio":              "%d. %s: %s, deuda en córdobas (%.2f%% anual)\n",
+		"debt.order.future":           "%d. %s: %s a partir del mes %d (%.2f%% anual)\n",
+		"debt.order.promo":            "%d. %s: %s (%.2f%% anual por %d meses, luego %.2f%% anual)\n",
+		"debt.order.standard":         "%d. %s: %s (%.2f%% anual)\n",
+		"debt.baseline":               "\n\nFrente a pagar solo los mínimos (%d meses y %s en intereses), este plan te ahorra %s en intereses y %d meses.",
+		"debt.quick_win":              "\n\nPara liquidar una deuda en los primeros %d meses se adelanta %s, que queda pagada en el mes %d; esto cuesta %s más en intereses que Avalanche puro.",
+		"debt.quick_win.unmet":        "\n\nNingún orden de pago liquida una deuda en los primeros %d meses con este presupuesto, así que se mantiene Avalanche puro.",
+		"debt.hardship":               "\n\nLos %d meses de dificultad alargan el plan %d meses y suman %s en intereses.",
+		"debt.recommendation":         "\n\nRecomendación: %s",
+		"debt.vs_avalanche.more_both": "\n\nComparado con Avalanche, pagarás %s más en intereses y tomará %d meses más, pero ofrece mayor motivación psicológica.",
+		"debt.vs_avalanche.sooner":    "\n\nComparado con Avalanche, terminarás %d meses antes pero pagarás %s más en intereses. Esto ocurre porque pagar deudas pequeñas primero libera capital más rápido, aunque puede resultar en un costo total mayor.",
+		"debt.vs_avalanche.same_time": "\n\nComparado con Avalanche, pagarás %s más en intereses en el mismo tiempo, pero con mayor motivación psicológica.",
+		"debt.vs_avalanche.later":     "\n\nComparado con Avalanche, pagarás los mismos intereses pero tomará %d meses más, aunque ofrece mayor motivación psicológica.",
+		"debt.vs_avalanche.faster":    "\n\nComparado con Avalanche, pagarás los mismos intereses y terminarás %d meses antes, combinando motivación con eficiencia temporal.",
+		"debt.vs_snowball.save_both":  "\n\nComparado con Snowball, ahorrarás %s en intereses y terminarás %d meses antes, minimizando tu costo financiero total.",
+		"debt.vs_snowball.later":      "\n\nComparado con Snowball, ahorrarás %s en intereses aunque tomará %d meses más. Esto ocurre porque priorizar deudas con mayor interés minimiza el costo total, aunque puede tomar más tiempo.",
+		"debt.vs_snowball.same_time":  "\n\nComparado con Snowball, ahorrarás %s en intereses en el mismo tiempo, optimizando el costo financiero.",
+		"debt.vs_snowball.faster":     "\n\nComparado con Snowball, pagarás los mismos intereses y terminarás %d meses antes, minimizando el tiempo total.",
+		"debt.vs_snowball.slower":     "\n\nComparado con Snowball, pagarás los mismos intereses pero tomará %d meses más, aunque minimiza el costo financiero.",
+
+		"term.minimize_interest":             "Este plazo de %d meses minimiza el costo total de intereses (%s), aunque requiere una cuota mensual de %s. El costo total del préstamo será %s. Esta opción es ideal si tu prioridad es reducir el costo financiero total en el mercado crediticio local.",
+		"term.minimize_payment":              "Este plazo de %d meses minimiza tu cuota mensual a %s, proporcionando mayor flexibilidad presupuestaria. Pagarás %s en intereses para un costo total de %s. Ideal para préstamos personales cuando necesitas maximizar tu capacidad de pago mensual.",
+		"term.fastest_payoff":                "Este plazo de %d meses es el más corto que cabe en tu presupuesto, con una cuota mensual de %s. Pagarás %s en intereses para un costo total de %s. Ideal si quieres quedar libre de la deuda cuanto antes.",
+		"term.minimize_total_cost_with_fees": "Este plazo de %d meses tiene el menor costo total incluyendo seguros y cargos, con una cuota mensual de %s. Pagarás %s en intereses para un costo total de %s.",
+		"term.balanced":                      "Este plazo de %d meses ofrece un balance óptimo entre cuota mensual (%s) y costo total de intereses (%s). El costo total del préstamo será %s. Esta recomendación equilibra tu capacidad de pago mensual con el costo financiero total en el contexto local.",
+
+		"benchmark.above":     "Tu tasa de %.2f%% está por encima de la tasa mediana de %.2f%% que vemos en %d cálculos de préstamos; vale la pena comparar otras ofertas.",
+		"benchmark.below":     "Tu tasa de %.2f%% está por debajo de la tasa mediana de %.2f%% que vemos en %d cálculos de préstamos.",
+		"benchmark.at_median": "Tu tasa de %.2f%% está en línea con la tasa mediana de %.2f%% que vemos en %d cálculos de préstamos.",
+
+		// Variantes por nivel de lectura; si no existe la variante se usa la clave base
+		"debt.summary.basic":          "Con el plan %s terminas de pagar todas tus deudas en %d meses (unos %.1f años). ",
+		"debt.summary.advanced":       "Bajo la estrategia %s la cartera se amortiza por completo en %d meses (%.1f años). ",
+		"debt.cost.basic":             "Hoy debes %s. Vas a pagar %s de intereses. En total pagarás %s. ",
+		"debt.cost.advanced":          "Saldo inicial: %s; intereses proyectados: %s; desembolso total: %s. ",
+		"debt.tip.snowball.basic":     "Pagas primero la deuda más pequeña. Así ves avances rápido y no pierdes el ánimo.",
+		"debt.tip.avalanche.basic":    "Pagas primero la deuda con el interés más alto. Así pagas menos en total.",
+		"debt.tip.snowball.advanced":  "Prioriza saldos menores para liberar pagos mínimos antes; el costo es un mayor interés acumulado frente a ordenar por tasa.",
+		"debt.tip.avalanche.advanced": "Prioriza la mayor tasa anual, lo que minimiza el interés total; el flujo de caja se libera más tarde que con saldos menores primero.",
+		"debt.health.low.basic":       "Tus deudas cobran en promedio %.2f%% al año y %.2f%% de tu pago del primer mes se va en intereses. Vas bien. ",
+		"debt.health.medium.basic":    "Tus deudas cobran en promedio %.2f%% al año y %.2f%% de tu pago del primer mes se va en intereses. Ten cuidado. ",
+		"debt.health.high.basic":      "Tus deudas cobran en promedio %.2f%% al año y %.2f%% de tu pago del primer mes se va en intereses. Es mucho: no pidas más préstamos. ",
+		"debt.health.low.advanced":    "APR promedio ponderado por saldo: %.2f%%; carga de intereses del mes 1: %.2f%% del presupuesto (riesgo bajo). ",
+		"debt.health.medium.advanced": "APR promedio ponderado por saldo: %.2f%%; carga de intereses del mes 1: %.2f%% del presupuesto (riesgo moderado). ",
+		"debt.health.high.advanced":   "APR promedio ponderado por saldo: %.2f%%; carga de intereses del mes 1: %.2f%% del presupuesto (riesgo alto). ",
+		"debt.baseline.basic":         "\n\nSi solo pagaras el mínimo tardarías %d meses y pagarías %s de intereses. Con este plan ahorras %s y terminas %d meses antes.",
+		"debt.baseline.advanced":      "\n\nLínea base de solo mínimos: %d meses y %s de intereses; el plan reduce el costo en %s y el plazo en %d meses.",
+
+		"term.minimize_interest.basic":    "Con %d meses pagas menos intereses (%s). Tu cuota es %s al mes. En total pagarás %s.",
+		"term.minimize_interest.advanced": "El plazo de %d meses minimiza el interés total (%s) a cambio de una cuota nivelada de %s; costo total del crédito: %s.",
+		"term.minimize_payment.basic":     "Con %d meses tu cuota baja a %s al mes. Pagarás %s de intereses. En total pagarás %s.",
+		"term.minimize_payment.advanced":  "El plazo de %d meses minimiza la cuota nivelada (%s) y mejora la holgura de flujo de caja; interés total: %s, costo total del crédito: %s.",
+		"term.balanced.basic":             "Con %d meses tu cuota es %s al mes y pagas %s de intereses. En total pagarás %s.",
+		"term.balanced.advanced":          "El plazo de %d meses equilibra la cuota nivelada (%s) y el interés total (%s); costo total del crédito: %s.",
+
+		"term.reason.minimize_interest":             "Plazo optimizado para minimizar el costo total de intereses",
+		"term.reason.minimize_payment":              "Plazo optimizado para minimizar el pago mensual",
+		"term.reason.balanced":                      "Balance óptimo entre pago mensual y costo total",
+		"term.reason.fastest_payoff":                "Plazo más corto que cabe en el presupuesto",
+		"term.reason.minimize_total_cost_with_fees": "Plazo optimizado para minimizar el costo total con seguros y cargos",
+		"term.rejected.payment":                     "la cuota de %s supera el pago mensual máximo de %s",
+		"term.rejected.error":                       "no se pudo calcular el plazo: %v",
+
+		"consolidation.summary":      "Consolidar tus deudas en un préstamo de %s a %d meses implica una cuota de %s y un costo de %s (%s en intereses y %s en comisiones). ",
+		"consolidation.strategy":     "Con la estrategia %s pagarías %s en intereses en %d meses.",
+		"consolidation.unaffordable": "\n\nRecomendación: la cuota del préstamo de consolidación excede tu pago mensual disponible, así que conviene mantener tus deudas y aplicar la estrategia %s.",
+		"consolidation.consolidate":  "\n\nRecomendación: consolidar te ahorra %s frente a %s. Evita usar de nuevo las tarjetas liberadas para no volver a endeudarte.",
+		"consolidation.keep":         "\n\nRecomendación: la consolidación cuesta %s más que %s; conviene mantener tus deudas y aplicar esa estrategia.",
+
+		"transfer.summary":    "Trasladar %s con una comisión de %s cambia tus intereses de %s a %s y tu plazo de %d a %d meses. ",
+		"transfer.saves":      "\n\nRecomendación: el traslado te ahorra %s netos",
+		"transfer.break_even": " y recuperas la comisión a partir del mes %d",
+		"transfer.promo_tip":  ". Aprovecha la promoción para abonar lo más posible antes de que suba la tasa.",
+		"transfer.costs":      "\n\nRecomendación: el traslado te cuesta %s más de lo que ahorras en intereses; no conviene con estas condiciones.",
+
+		"rate_change.same": "Con la tasa de %.2f%% tu cuota se mantiene en %s durante los %d meses restantes.",
+		"rate_change.up":   "Al pasar de %.2f%% a %.2f%%, tu cuota sube de %s a %s y pagarás %s más en intereses durante los %d meses restantes.",
+		"rate_change.down": "Al pasar de %.2f%% a %.2f%%, tu cuota baja de %s a %s y pagarás %s menos en intereses durante los %d meses restantes.",
+	},
+	"en": {
+		"debt.strategy.snowball":      "Snowball",
+		"debt.strategy.avalanche":     "Avalanche",
+		"debt.tip.snowball":           "Ideal if you need quick wins to stay motivated. Every debt you pay off frees up money you can apply to the next one.",
+		"debt.tip.avalanche":          "Ideal if your main goal is to minimize the total financial cost. It takes discipline but maximizes savings.",
+		"debt.strategy.cfi":           "Cash Flow Index (CFI)",
+		"debt.tip.cfi":                "Ideal if you need to free up monthly cash flow as soon as possible. Each debt you pay off removes the largest minimum payment relative to its balance.",
+		"debt.tip.cfi.basic":          "You pay first the debt with the biggest minimum payment for what you owe. You have more money free each month.",
+		"debt.tip.cfi.advanced":       "Prioritizes the lowest balance-to-minimum-payment index, maximizing cash flow freed per dollar paid; total interest usually falls between Snowball and Avalanche.",
+		"debt.vs_cfi":                 "\n\nThe Cash Flow Index (CFI) strategy would pay off your debts in %d months with %s in interest.",
+		"debt.cfi_vs_others":          "\n\nCompared with Snowball (%d months, %s in interest) and Avalanche (%d months, %s in interest), Cash Flow Index is the lowest-cost option for your debts.",
+		"debt.strategy.weighted":      "Weighted (rate and balance)",
+		"debt.tip.weighted":           "Ideal if you want a middle ground between Snowball and Avalanche: shift the weights toward the rate to save interest or toward the balance to see debts paid off sooner.",
+		"debt.tip.weighted.basic":     "You pay first the debt that combines more interest and a smaller balance. You can change the weights to save more or move faster.",
+		"debt.tip.weighted.advanced":  "Orders by a linear combination of the normalized APR and balance; a rate weight of 1 matches Avalanche and a balance weight of 1 matches Snowball.",
+		"debt.weights":                "Each debt is prioritized with a %.0f%% weight on the interest rate and %.0f%% on the balance. ",
+		"debt.summary":                "The %s strategy will let you pay off all your debts in %d months (%.1f years). ",
+		"debt.fees":                   "The total cost includes %s in recurring fees (annual and maintenance fees). ",
+		"debt.cost":                   "Your starting debt is %s and you will pay %s in interest, for a total cost of %s. ",
+		"debt.health.low":             "Your weighted average rate is %.2f%% APR and %.2f%% of your first month's payment goes to interest, a low risk level. ",
+		"debt.health.medium":          "Your weighted average rate is %.2f%% APR and %.2f%% of your first month's payment goes to interest, a moderate risk level. ",
+		"debt.health.high":            "Your weighted average rate is %.2f%% APR and %.2f%% of your first month's payment goes to interest, a high risk level: prioritize the most expensive debts and avoid taking on new debt. ",
+		"debt.health.dti":             "Your minimum payments are %.2f%% of your monthly income. ",
+		"debt.order":                  "\n\nWith %s, the payoff order is:\n",
+		"debt.order.nio":              "%d. %s: %s, debt in córdobas (%.2f%% APR)\n",
+		"debt.order.future":           "%d. %s: %s starting in month %d (%.2f%% APR)\n",
+		"debt.order.promo":            "%d. %s: %s (%.2f%% APR for %d months, then %.2f%% APR)\n",
+		"debt.order.standard":         "%d. %s: %s (%.2f%% APR)\n",
+		"debt.baseline":               "\n\nCompared with paying only the minimums (%d months and %s in interest), this plan saves you %s in interest and %d months.",
+		"debt.quick_win":              "\n\nTo pay off a debt within the first %d months, %s is moved to the front and is paid off in month %d; this costs %s more in interest than pure Avalanche.",
+		"debt.quick_win.unmet":        "\n\nNo payment order pays off a debt within the first %d months with this budget, so pure Avalanche is kept.",
+		"debt.hardship":               "\n\nThe %d hardship months extend the plan by %d months and add %s in interest.",
+		"debt.recommendation":         "\n\nRecommendation: %s",
+		"debt.vs_avalanche.more_both": "\n\nCompared with Avalanche, you will pay %s more in interest and it will take %d more months, but it offers stronger psychological motivation.",
+		"debt.vs_avalanche.sooner":    "\n\nCompared with Avalanche, you will finish %d months sooner but pay %s more in interest. Paying small debts first frees up money faster, although it can cost more overall.",
+		"debt.vs_avalanche.same_time": "\n\nCompared with Avalanche, you will pay %s more in interest over the same time, but with stronger psychological motivation.",
+		"debt.vs_avalanche.later":     "\n\nCompared with Avalanche, you will pay the same interest but it will take %d more months, although it offers stronger psychological motivation.",
+		"debt.vs_avalanche.faster":    "\n\nCompared with Avalanche, you will pay the same interest and finish %d months sooner, combining motivation with speed.",
+		"debt.vs_snowball.save_both":  "\n\nCompared with Snowball, you will save %s in interest and finish %d months sooner, minimizing your total financial cost.",
+		"debt.vs_snowball.later":      "\n\nCompared with Snowball, you will save %s in interest although it will take %d more months. Prioritizing the highest-interest debts minimizes the total cost, even if it can take longer.",
+		"debt.vs_snowball.same_time":  "\n\nCompared with Snowball, you will save %s in interest over the same time, optimizing the financial cost.",
+		"debt.vs_snowball.faster":     "\n\nCompared with Snowball, you will pay the same interest and finish %d months sooner, minimizing the total time.",
+		"debt.vs_snowball.slower":     "\n\nCompared with Snowball, you will pay the same interest but it will take %d more months, although it minimizes the financial cost.",
+
+		"term.minimize_interest":             "This %d-month term minimizes the total interest cost (%s), although it requires a monthly payment of %s. The total cost of the loan will be %s. This option is ideal if your priority is reducing the total financial cost in the local credit market.",
+		"term.minimize_payment":              "This %d-month term lowers your monthly payment to %s, giving you more budget flexibility. You will pay %s in interest for a total cost of %s. Ideal for personal loans when you need to maximize your monthly payment capacity.",
+		"term.fastest_payoff":                "This %d-month term is the shortest that fits your budget, with a monthly payment of %s. You will pay %s in interest for a total cost of %s. Ideal if you want to be debt-free as soon as possible.",
+		"term.minimize_total_cost_with_fees": "This %d-month term has the lowest total cost including insurance and fees, with a monthly payment of %s. You will pay %s in interest for a total cost of %s.",
+		"term.balanced":                      "This %d-month term offers the best balance between monthly payment (%s) and total interest cost (%s). The total cost of the loan will be %s. This recommendation balances your monthly payment capacity with the total financial cost in the local context.",
+
+		"benchmark.above":     "Your %.2f%% rate is above the median %.2f%% rate we see across %d loan calculations; it is worth comparing other offers.",
+		"benchmark.below":     "Your %.2f%% rate is below the median %.2f%% rate we see across %d loan calculations.",
+		"benchmark.at_median": "Your %.2f%% rate is in line with the median %.2f%% rate we see across %d loan calculations.",
+
+		// Reading-level variants; the base key is used when a variant is missing
+		"debt.summary.basic":          "With the %s plan you finish paying all your debts in %d months (about %.1f years). ",
+		"debt.summary.advanced":       "Under the %s strategy the portfolio fully amortizes in %d months (%.1f years). ",
+		"debt.cost.basic":             "Today you owe %s. You will pay %s in interest. In total you will pay %s. ",
+		"debt.cost.advanced":          "Starting balance: %s; projected interest: %s; total outlay: %s. ",
+		"debt.tip.snowball.basic":     "You pay the smallest debt first. You see progress fast and stay motivated.",
+		"debt.tip.avalanche.basic":    "You pay the debt with the highest interest first. You pay less in total.",
+		"debt.tip.snowball.advanced":  "Prioritizes smaller balances to free up minimum payments sooner, at the cost of more cumulative interest than ordering by rate.",
+		"debt.tip.avalanche.advanced": "Prioritizes the highest APR, which minimizes total interest; cash flow frees up later than with smallest balances first.",
+		"debt.health.low.basic":       "Your debts charge %.2f%% a year on average and %.2f%% of your first month's payment goes to interest. You are doing well. ",
+		"debt.health.medium.basic":    "Your debts charge %.2f%% a year on average and %.2f%% of your first month's payment goes to interest. Be careful. ",
+		"debt.health.high.basic":      "Your debts charge %.2f%% a year on average and %.2f%% of your first month's payment goes to interest. That is a lot: do not take new loans. ",
+		"debt.health.low.advanced":    "Balance-weighted APR: %.2f%%; month-1 interest load: %.2f%% of budget (low risk). ",
+		"debt.health.medium.advanced": "Balance-weighted APR: %.2f%%; month-1 interest load: %.2f%% of budget (moderate risk). ",
+		"debt.health.high.advanced":   "Balance-weighted APR: %.2f%%; month-1 interest load: %.2f%% of budget (high risk). ",
+		"debt.baseline.basic":         "\n\nIf you only paid the minimum it would take %d months and %s in interest. With this plan you save %s and finish %d months sooner.",
+		"debt.baseline.advanced":      "\n\nMinimums-only baseline: %d months and %s in interest; the plan cuts cost by %s and term by %d months.",
+
+		"term.minimize_interest.basic":    "With %d months you pay less interest (%s). Your payment is %s a month. In total you will pay %s.",
+		"term.minimize_interest.advanced": "The %d-month term minimizes total interest (%s) in exchange for a level payment of %s; total cost of credit: %s.",
+		"term.minimize_payment.basic":     "With %d months your payment drops to %s a month. You will pay %s in interest. In total you will pay %s.",
+		"term.minimize_payment.advanced":  "The %d-month term minimizes the level payment (%s) and improves cash-flow headroom; total interest: %s, total cost of credit: %s.",
+		"term.balanced.basic":             "With %d months your payment is %s a month and you pay %s in interest. In total you will pay %s.",
+		"term.balanced.advanced":          "The %d-month term balances the level payment (%s) and total interest (%s); total cost of credit: %s.",
+
+		"term.reason.minimize_interest":             "Term optimized to minimize total interest cost",
+		"term.reason.minimize_payment":              "Term optimized to minimize the monthly payment",
+		"term.reason.balanced":                      "Best balance between monthly payment and total cost",
+		"term.reason.fastest_payoff":                "Shortest term that fits the budget",
+		"term.reason.minimize_total_cost_with_fees": "Term optimized to minimize total cost including insurance and fees",
+		"term.rejected.payment":                     "the payment of %s exceeds the maximum monthly payment of %s",
+		"term.rejected.error":                       "the term could not be calculated: %v",
+
+		"consolidation.summary":      "Consolidating your debts into a %s loan over %d months means a payment of %s and a cost of %s (%s in interest and %s in fees). ",
+		"consolidation.strategy":     "With the %s strategy you would pay %s in interest over %d months.",
+		"consolidation.unaffordable": "\n\nRecommendation: the consolidation loan payment exceeds your available monthly payment, so it is better to keep your debts and apply the %s strategy.",
+		"consolidation.consolidate":  "\n\nRecommendation: consolidating saves you %s compared to %s. Avoid using the freed-up cards again so you do not fall back into debt.",
+		"consolidation.keep":         "\n\nRecommendation: consolidation costs %s more than %s; it is better to keep your debts and apply that strategy.",
+
+		"transfer.summary":    "Transferring %s with a fee of %s changes your interest from %s to %s and your term from %d to %d months. ",
+		"transfer.saves":      "\n\nRecommendation: the transfer saves you %s net",
+		"transfer.break_even": " and you recover the fee from month %d",
+		"transfer.promo_tip":  ". Use the promotion to pay down as much as possible before the rate goes up.",
+		"transfer.costs":      "\n\nRecommendation: the transfer costs you %s more than you save in interest; it is not worth it under these terms.",
+
+		"rate_change.same": "At a rate of %.2f%% your payment stays at %s for the remaining %d months.",
+		"rate_change.up":   "Going from %.2f%% to %.2f%%, your payment rises from %s to %s and you will pay %s more in interest over the remaining %d months.",
+		"rate_change.down": "Going from %.2f%% to %.2f%%, your payment drops from %s to %s and you will pay %s less in interest over the remaining %d months.",
+	},
+}
+
+// ReadingLevels lista los niveles de lectura de las explicaciones; vacío equivale a "standard"
+var ReadingLevels = map[string]bool{
+	"":         true,
+	"basic":    true,
+	"standard": true,
+	"advanced": true,
+}
+
+// explanationOptions selecciona el idioma y el nivel de lectura de una explicación
+type explanationOptions struct {
+	Language     string
+	ReadingLevel string
+}
+
+// text formatea la plantilla de la clave en el idioma elegido, usando la
+// variante del nivel de lectura si existe. Si el idioma no existe se usa el
+// idioma por defecto.
+func (o explanationOptions) text(key string, args ...any) string {
+	messages, ok := explanationMessages[o.Language]
+	if !ok {
+		messages = explanationMessages[DefaultLanguage]
+	}
+	template, ok := messages[key+"."+o.ReadingLevel]
+	if !ok {
+		template = messages[key]
+	}
+	return fmt.Sprintf(template, args...)
+}
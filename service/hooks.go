@@ -0,0 +1,160 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// HookStage es el punto del ciclo de una request en el que corre un hook
+type HookStage string
+
+const (
+	// HookPreValidate recibe el cuerpo de la request antes de validarlo
+	HookPreValidate HookStage = "pre_validate"
+	// HookPostCalculate recibe el resultado de un cálculo exitoso
+	HookPostCalculate HookStage = "post_calculate"
+	// HookPreRespond recibe cualquier respuesta, incluidos los errores, antes de enviarla
+	HookPreRespond HookStage = "pre_respond"
+)
+
+// HookStages lista las etapas en el orden en que corren
+var HookStages = []HookStage{HookPreValidate, HookPostCalculate, HookPreRespond}
+
+// HookEvent es lo que recibe un hook: la etapa, el endpoint y el JSON de la
+// request o de la respuesta según la etapa
+type HookEvent struct {
+	Stage    HookStage
+	Endpoint string
+	Status   int `json:",omitempty"` // status de la respuesta en post_calculate y pre_respond
+	Payload  json.RawMessage
+}
+
+// HookVetoError indica que un hook rechazó la request
+type HookVetoError struct {
+	Reason string
+}
+
+func (e *HookVetoError) Error() string {
+	return "solicitud rechazada: " + e.Reason
+}
+
+// Hook enriquece un payload devolviendo uno nuevo (nil lo deja igual) o lo
+// veta devolviendo un *HookVetoError. Cualquier otro error se registra y se ignora.
+type Hook interface {
+	Run(ctx context.Context, event HookEvent) (json.RawMessage, error)
+}
+
+// HookFunc permite registrar una función Go como hook
+type HookFunc func(ctx context.Context, event HookEvent) (json.RawMessage, error)
+
+func (f HookFunc) Run(ctx context.Context, event HookEvent) (json.RawMessage, error) {
+	return f(ctx, event)
+}
+
+// HookRegistry guarda los hooks de cada etapa en orden de registro. Las reglas
+// propias de una institución se registran en main sin modificar los servicios.
+type HookRegistry struct {
+	hooks map[HookStage][]Hook
+}
+
+func NewHookRegistry() *HookRegistry {
+	return &HookRegistry{hooks: make(map[HookStage][]Hook)}
+}
+
+// Register agrega un hook a la etapa; debe llamarse antes de servir tráfico
+func (r *HookRegistry) Register(stage HookStage, hook Hook) {
+	r.hooks[stage] = append(r.hooks[stage], hook)
+}
+
+// Has indica si la etapa tiene hooks registrados
+func (r *HookRegistry) Has(stage HookStage) bool {
+	return len(r.hooks[stage]) > 0
+}
+
+// Empty indica si no hay ningún hook registrado
+func (r *HookRegistry) Empty() bool {
+	return len(r.hooks) == 0
+}
+
+// Run ejecuta en orden los hooks de la etapa; cada hook recibe el payload que
+// dejó el anterior. Devuelve el payload final o el veto del primer hook que rechace.
+func (r *HookRegistry) Run(ctx context.Context, event HookEvent) (json.RawMessage, error) {
+	for _, hook := range r.hooks[event.Stage] {
+		payload, err := hook.Run(ctx, event)
+		var veto *HookVetoError
+		if errors.As(err, &veto) {
+			return nil, veto
+		}
+		if err != nil {
+			slog.Warn("hook failed", "stage", event.Stage, "route", event.Endpoint, "error", err)
+			continue
+		}
+		if payload != nil {
+			event.Payload = payload
+		}
+	}
+	return event.Payload, nil
+}
+
+// WebhookHook envía el evento como JSON a un webhook. El webhook responde 204
+// para no cambiar nada, 200 con el payload que lo reemplaza o 422 con el
+// motivo del veto en el cuerpo.
+type WebhookHook struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookHook(url string) *WebhookHook {
+	return &WebhookHook{
+		url:    url,
+		client: &http.Client{Timeout: HookTimeout},
+	}
+}
+
+func (h *WebhookHook) Run(ctx context.Context, event HookEvent) (json.RawMessage, error) {
+	if IsSandbox(ctx) {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, MaxHookResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusOK:
+		if !json.Valid(respBody) {
+			return nil, errors.New("hook webhook returned invalid JSON")
+		}
+		return respBody, nil
+	case http.StatusUnprocessableEntity:
+		return nil, &HookVetoError{Reason: strings.TrimSpace(string(respBody))}
+	}
+	return nil, fmt.Errorf("hook webhook returned status %d", resp.StatusCode)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"strings"
+
+	"loan-agent/domain"
+)
+
+// suggestTermCorrection detecta plazos tan cortos que parecen estar en años
+func suggestTermCorrection(field string, termMonths int) []domain.InputSuggestion {
+	if termMonths <= 0 || termMonths > SuspiciousTermMonths {
+		return nil
+	}
+	suggested := termMonths * 12
+	return []domain.InputSuggestion{{
+		Field:          field,
+		Value:          float64(termMonths),
+		SuggestedValue: float64(suggested),
+		Code:           "suggestion.term",
+		Args:           []any{suggested, termMonths},
+	}}
+}
+
+// suggestRateCorrection detecta tasas tan bajas que parecen ser mensuales
+func suggestRateCorrection(field string, rate float64) []domain.InputSuggestion {
+	if rate <= 0 || rate > SuspiciousMonthlyRate {
+		return nil
+	}
+	suggested := roundTo2Decimals(rate * 12)
+	return []domain.InputSuggestion{{
+		Field:          field,
+		Value:          rate,
+		SuggestedValue: suggested,
+		Code:           "suggestion.rate",
+		Args:           []any{rate, suggested},
+	}}
+}
+
+// suggestAmountCorrection detecta montos pequeños a plazos largos, típicos de
+// haber omitido los tres ceros de los miles
+func suggestAmountCorrection(field string, amount float64, termMonths int) []domain.InputSuggestion {
+	if amount <= 0 || amount >= SuspiciousLoanAmount || termMonths < SuspiciousAmountMinTerm {
+		return nil
+	}
+	suggested := amount * 1000
+	return []domain.InputSuggestion{{
+		Field:          field,
+		Value:          amount,
+		SuggestedValue: suggested,
+		Code:           "suggestion.amount",
+		Args:           []any{suggested, amount, termMonths},
+	}}
+}
+
+// localizeSuggestions devuelve las sugerencias con el mensaje en el idioma pedido
+func localizeSuggestions(suggestions []domain.InputSuggestion, lang string) []domain.InputSuggestion {
+	if len(suggestions) == 0 {
+		return nil
+	}
+	localized := make([]domain.InputSuggestion, len(suggestions))
+	for i, suggestion := range suggestions {
+		suggestion.Message = errorText(lang, suggestion.Code, suggestion.Args...)
+		localized[i] = suggestion
+	}
+	return localized
+}
+
+// suggestionsError agrega las sugerencias al mensaje de err; se localizan
+// junto con el error al escribir la respuesta
+type suggestionsError struct {
+	err         error
+	suggestions []domain.InputSuggestion
+}
+
+// withSuggestions agrega las sugerencias al mensaje de un error de validación
+func withSuggestions(err error, suggestions []domain.InputSuggestion) error {
+	if len(suggestions) == 0 {
+		return err
+	}
+	return &suggestionsError{err: err, suggestions: suggestions}
+}
+
+func (e *suggestionsError) Error() string {
+	return e.localize(DefaultLanguage)
+}
+
+func (e *suggestionsError) localize(lang string) string {
+	suggestions := localizeSuggestions(e.suggestions, lang)
+	messages := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		messages[i] = suggestion.Message
+	}
+	return errorText(lang, "with_suggestions", LocalizedMessage(e.err, lang), strings.Join(messages, ". "))
+}
+
+func (e *suggestionsError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+
+	"loan-agent/domain"
+)
+
+// loanQuoteKey identifica un cálculo por los datos que determinan su resultado
+func loanQuoteKey(input domain.LoanInput) string {
+	data, _ := json.Marshal(struct {
+		Amount       float64
+		InterestRate float64
+		TermMonths   int
+		Insurances   []domain.Insurance
+	}{input.Amount, input.InterestRate, input.TermMonths, input.Insurances})
+	sum := sha256.Sum256(data)
+
+	return "loan_quote:" + hex.EncodeToString(sum[:])
+}
+
+// quoteLoan calcula un préstamo interno (sin guardarlo) usando el cache como
+// memo compartido entre requests: las recomendaciones repetidas para el mismo
+// monto, tasa, plazo y seguros no recalculan. Las entradas vencen a los
+// LoanQuoteTTL; no piden la tabla de amortización.
+func (s *LoanService) quoteLoan(input domain.LoanInput) (domain.LoanResult, error) {
+	key := loanQuoteKey(input)
+	if cached, ok := s.cache.Get(key); ok {
+		var result domain.LoanResult
+		if err := json.Unmarshal([]byte(cached), &result); err == nil {
+			return result, nil
+		}
+		slog.Warn("ignoring invalid cached loan quote", "key", key)
+	}
+
+	result, err := s.calculateLoan(input, false)
+	if err != nil {
+		return domain.LoanResult{}, err
+	}
+
+	// Guardar en el memo (no crítico si falla)
+	if data, err := json.Marshal(result); err == nil {
+		if err := s.cache.SetWithTTL(key, string(data), LoanQuoteTTL); err != nil {
+			slog.Warn("failed to cache loan quote", "error", err)
+		}
+	}
+
+	return result, nil
+}
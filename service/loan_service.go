@@ -1,10 +1,10 @@
 package service
 
 import (
-	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
+	"strings"
 
 	"loan-agent/domain"
 	"loan-agent/repository"
@@ -28,54 +28,290 @@ func NewLoanService(repo repository.LoanRepository,
 }
 
 // CalculateLoan calculates the loan details based on the input parameters.
+// Sandbox calculations are not saved.
 func (s *LoanService) CalculateLoan(
 	input domain.LoanInput,
 ) (domain.LoanResult, error) {
+	return s.calculateLoan(input, !input.Sandbox)
+}
 
-	if input.Amount <= 0 {
-		return domain.LoanResult{}, errors.New("monto inválido")
-	}
-	if input.Amount > MaxLoanAmount {
-		return domain.LoanResult{}, fmt.Errorf("monto excede el máximo permitido de $%.2f", MaxLoanAmount)
-	}
-	if input.InterestRate < 0 {
-		return domain.LoanResult{}, errors.New("tasa inválida")
+// calculateLoan valida y calcula el préstamo; save indica si el cálculo se
+// guarda. Los cálculos internos (plazos candidatos, ofertas) no son cálculos
+// del usuario y no se guardan para no alterar el historial ni la mediana de tasas
+func (s *LoanService) calculateLoan(
+	input domain.LoanInput,
+	save bool,
+) (domain.LoanResult, error) {
+
+	var v fieldValidator
+	switch {
+	case input.Amount <= 0:
+		v.add("Amount", validationError("INVALID_AMOUNT"))
+	case input.Amount > MaxLoanAmount:
+		v.add("Amount", validationError("AMOUNT_TOO_LARGE", MaxLoanAmount))
 	}
-	if input.InterestRate > MaxInterestRate {
-		return domain.LoanResult{}, fmt.Errorf("tasa de interés excede el máximo permitido de %.2f%%", MaxInterestRate)
+	switch {
+	case input.InterestRate < 0:
+		v.add("InterestRate", validationError("INVALID_INTEREST_RATE"))
+	case input.InterestRate > MaxInterestRate:
+		v.add("InterestRate", validationError("INTEREST_RATE_TOO_HIGH", MaxInterestRate))
 	}
-	if input.TermMonths <= 0 {
-		return domain.LoanResult{}, errors.New("plazo inválido")
+	switch {
+	case input.TermMonths <= 0:
+		v.add("TermMonths", validationError("INVALID_TERM"))
+	case input.TermMonths > MaxTermMonths:
+		v.add("TermMonths", validationError("TERM_TOO_LONG", MaxTermMonths))
 	}
-	if input.TermMonths > MaxTermMonths {
-		return domain.LoanResult{}, fmt.Errorf("plazo excede el máximo permitido de %d meses", MaxTermMonths)
+	validateInsurances(&v, input.Insurances)
+	language, err := validateLanguage(input.Language)
+	v.check("Language", err)
+	tags, err := normalizeTags(input.Tags)
+	v.check("Tags", err)
+	validateCollateral(&v, input.Collateral)
+	if err := v.err(); err != nil {
+		return domain.LoanResult{}, err
 	}
+	input.Tags = tags
 
-	var cuota float64
-
-	if input.InterestRate == 0 {
-		cuota = input.Amount / float64(input.TermMonths)
-	} else {
-		tasaMensual := (input.InterestRate / 100) / 12
-		n := float64(input.TermMonths)
+	collateral, warnings, err := assessCollateral(input, language)
+	if err != nil {
+		return domain.LoanResult{}, err
+	}
 
-		cuota = input.Amount * (tasaMensual /
-			(1 - math.Pow(1+tasaMensual, -n)))
+	// La comparación se hace antes de guardar para no contar este mismo cálculo
+	var benchmark *domain.RateBenchmark
+	if input.IncludeBenchmark {
+		benchmark = s.rateBenchmark(input.InterestRate, language)
 	}
 
+	cuota := monthlyPayment(input.Amount, input.InterestRate, input.TermMonths)
+
 	total := cuota * float64(input.TermMonths)
 	intereses := total - input.Amount
 
+	// La tabla de amortización es opcional: los cálculos internos solo usan los totales
+	var schedule []domain.AmortizationEntry
+	primerSeguro, totalSeguro := insurancePremiums(input, cuota)
+	if input.IncludeSchedule {
+		schedule, _ = buildAmortizationSchedule(input, cuota)
+	}
+
 	result := domain.LoanResult{
-		MonthlyPayment: roundTo2Decimals(cuota),
-		TotalPayment:   roundTo2Decimals(total),
-		TotalInterest:  roundTo2Decimals(intereses),
+		MonthlyPayment:   roundTo2Decimals(cuota),
+		MonthlyInsurance: roundTo2Decimals(primerSeguro),
+		TotalPayment:     roundTo2Decimals(total),
+		TotalInterest:    roundTo2Decimals(intereses),
+		TotalInsurance:   roundTo2Decimals(totalSeguro),
+		Schedule:         schedule,
+		Collateral:       collateral,
+		Warnings:         warnings,
+		Suggestions:      localizeSuggestions(loanInputSuggestions(input.Amount, input.InterestRate, input.TermMonths), language),
+		Benchmark:        benchmark,
 	}
 
 	// Guardar el resultado (no crítico si falla)
-	if err := s.repo.Save(input, result); err != nil {
-		log.Printf("Warning: failed to save loan calculation: %v", err)
+	if save {
+		if err := s.repo.Save(input, result); err != nil {
+			slog.Warn("failed to save loan calculation", "error", err)
+		}
 	}
 
 	return result, nil
 }
+
+func loanInputSuggestions(amount, rate float64, termMonths int) []domain.InputSuggestion {
+	suggestions := suggestTermCorrection("TermMonths", termMonths)
+	suggestions = append(suggestions, suggestRateCorrection("InterestRate", rate)...)
+	return append(suggestions, suggestAmountCorrection("Amount", amount, termMonths)...)
+}
+
+// monthlyPayment calcula la cuota fija (sistema francés) sin seguros
+func monthlyPayment(amount, annualRate float64, termMonths int) float64 {
+	if annualRate == 0 {
+		return amount / float64(termMonths)
+	}
+
+	tasaMensual := (annualRate / 100) / 12
+	n := float64(termMonths)
+
+	return amount * (tasaMensual /
+		(1 - math.Pow(1+tasaMensual, -n)))
+}
+
+// ListCalculations devuelve los cálculos guardados del usuario, filtrados por tag si se indica
+func (s *LoanService) ListCalculations(userID, tag string) ([]domain.LoanRecord, error) {
+	return s.repo.List(userID, strings.ToLower(strings.TrimSpace(tag)))
+}
+
+// ListTags devuelve los tags en uso por el usuario con su número de cálculos
+func (s *LoanService) ListTags(userID string) ([]domain.TagCount, error) {
+	return s.repo.Tags(userID)
+}
+
+// validateCollateral valida el tipo y el avalúo de la garantía, si hay
+func validateCollateral(v *fieldValidator, collateral *domain.Collateral) {
+	if collateral == nil {
+		return
+	}
+	if _, ok := GetMaxLTV(collateral.Type); !ok {
+		v.add("Collateral.Type", validationError("INVALID_COLLATERAL_TYPE"))
+	}
+	if collateral.AppraisedValue <= 0 {
+		v.add("Collateral.AppraisedValue", validationError("INVALID_APPRAISED_VALUE"))
+	}
+}
+
+// assessCollateral calcula el LTV del préstamo y lo compara con el máximo
+// permitido para el tipo de garantía; la garantía ya pasó validateCollateral.
+// Las advertencias se redactan en el idioma language
+func assessCollateral(
+	input domain.LoanInput,
+	language string,
+) (*domain.CollateralAssessment, []string, error) {
+	if input.Collateral == nil {
+		return nil, nil, nil
+	}
+
+	maxLTV, _ := GetMaxLTV(input.Collateral.Type)
+	ltv := input.Amount / input.Collateral.AppraisedValue * 100
+	maxFinanceable := input.Collateral.AppraisedValue * maxLTV / 100
+
+	var warnings []string
+	if ltv > maxLTV {
+		if GetLTVEnforcement() == "reject" {
+			return nil, nil, validationError("LTV_EXCEEDED", maxLTV, maxFinanceable)
+		}
+		warnings = append(warnings, errorText(language, "warning.ltv_exceeded", ltv, maxLTV, maxFinanceable))
+	}
+
+	return &domain.CollateralAssessment{
+		Type:                 input.Collateral.Type,
+		AppraisedValue:       input.Collateral.AppraisedValue,
+		LoanToValue:          roundTo2Decimals(ltv),
+		MaxLoanToValue:       maxLTV,
+		MaxFinanceableAmount: roundTo2Decimals(maxFinanceable),
+	}, warnings, nil
+}
+
+// normalizeTags limpia, pasa a minúsculas y elimina tags duplicados
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) > MaxTagsPerRequest {
+		return nil, validationError("TOO_MANY_TAGS", MaxTagsPerRequest)
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, validationError("EMPTY_TAG")
+		}
+		if len([]rune(tag)) > MaxTagLength {
+			return nil, validationError("TAG_TOO_LONG", MaxTagLength)
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized, nil
+}
+
+// validateInsurances valida los seguros asociados al préstamo
+func validateInsurances(v *fieldValidator, insurances []domain.Insurance) {
+	for i, insurance := range insurances {
+		field := fmt.Sprintf("Insurances[%d]", i)
+		switch insurance.Type {
+		case "percentage":
+			if insurance.Value > MaxInsuranceRate {
+				v.add(field+".Value", validationError("INSURANCE_RATE_TOO_HIGH", MaxInsuranceRate))
+			}
+		case "flat":
+		default:
+			v.add(field+".Type", validationError("INVALID_INSURANCE_TYPE"))
+		}
+		if insurance.Value < 0 {
+			v.add(field+".Value", validationError("INVALID_INSURANCE_VALUE"))
+		}
+	}
+}
+
+// monthlyInsurance calcula la prima de seguros del mes sobre el saldo inicial del mes
+func monthlyInsurance(insurances []domain.Insurance, balance float64) float64 {
+	premium := 0.0
+	for _, insurance := range insurances {
+		if insurance.Type == "percentage" {
+			premium += balance * insurance.Value / 100
+		} else {
+			premium += insurance.Value
+		}
+	}
+	return premium
+}
+
+// amortize recorre los meses del préstamo; each recibe el mes con su capital,
+// interés, prima de seguros (sobre el saldo inicial) y saldo final
+func amortize(
+	input domain.LoanInput,
+	cuota float64,
+	each func(month int, capital, interes, seguro, balance float64),
+) {
+	tasaMensual := (input.InterestRate / 100) / 12
+	balance := input.Amount
+
+	for month := 1; month <= input.TermMonths; month++ {
+		interes := balance * tasaMensual
+		seguro := monthlyInsurance(input.Insurances, balance)
+		capital := cuota - interes
+		// El último mes liquida cualquier residuo de redondeo
+		if month == input.TermMonths || capital > balance {
+			capital = balance
+		}
+		balance -= capital
+		each(month, capital, interes, seguro, balance)
+	}
+}
+
+// buildAmortizationSchedule genera la tabla de amortización con los seguros incluidos
+// y devuelve el total pagado en seguros
+func buildAmortizationSchedule(
+	input domain.LoanInput,
+	cuota float64,
+) ([]domain.AmortizationEntry, float64) {
+	schedule := make([]domain.AmortizationEntry, 0, input.TermMonths)
+	totalSeguro := 0.0
+	amortize(input, cuota, func(month int, capital, interes, seguro, balance float64) {
+		totalSeguro += seguro
+		schedule = append(schedule, domain.AmortizationEntry{
+			Month:            month,
+			Payment:          roundTo2Decimals(capital + interes + seguro),
+			Principal:        roundTo2Decimals(capital),
+			Interest:         roundTo2Decimals(interes),
+			Insurance:        roundTo2Decimals(seguro),
+			RemainingBalance: roundTo2Decimals(balance),
+		})
+	})
+
+	return schedule, totalSeguro
+}
+
+// insurancePremiums devuelve la prima del primer mes (la más alta cuando el
+// seguro se calcula sobre el saldo) y el total pagado en seguros, sin armar la
+// tabla de amortización
+func insurancePremiums(input domain.LoanInput, cuota float64) (float64, float64) {
+	if len(input.Insurances) == 0 {
+		return 0, 0
+	}
+	first, total := 0.0, 0.0
+	amortize(input, cuota, func(month int, _, _, seguro, _ float64) {
+		if month == 1 {
+			first = seguro
+		}
+		total += seguro
+	})
+	return first, total
+}
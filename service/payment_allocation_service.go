@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"loan-agent/domain"
+)
+
+const defaultAccrualDays = 30
+
+type PaymentAllocationService struct{}
+
+func NewPaymentAllocationService() *PaymentAllocationService {
+	return &PaymentAllocationService{}
+}
+
+// AllocatePayment muestra cómo se aplica un pago hoy: primero a comisiones,
+// luego al interés devengado y el resto a capital
+func (s *PaymentAllocationService) AllocatePayment(
+	input domain.PaymentAllocationInput,
+) (domain.PaymentAllocationResult, error) {
+
+	var v fieldValidator
+	if input.Balance <= 0 || input.Balance > MaxDebtAmount {
+		v.add("Balance", validationError("INVALID_BALANCE"))
+	}
+	if input.InterestRate < 0 || input.InterestRate > MaxInterestRate {
+		v.add("InterestRate", validationError("INVALID_INTEREST_RATE"))
+	}
+	if input.Payment <= 0 {
+		v.add("Payment", validationError("INVALID_PAYMENT"))
+	}
+	if input.FeesDue < 0 {
+		v.add("FeesDue", validationError("INVALID_PENDING_FEES"))
+	}
+	interest, err := accruedInterest(input)
+	if ErrorCode(err) == "INVALID_ACCRUAL_DAYS" {
+		v.add("DaysSinceLastPayment", err)
+	} else {
+		v.check("AccrualConvention", err)
+	}
+	if err := v.err(); err != nil {
+		return domain.PaymentAllocationResult{}, err
+	}
+
+	remaining := input.Payment
+	toFees := math.Min(remaining, input.FeesDue)
+	remaining -= toFees
+	toInterest := math.Min(remaining, interest)
+	remaining -= toInterest
+	toPrincipal := math.Min(remaining, input.Balance)
+	remaining -= toPrincipal
+
+	result := domain.PaymentAllocationResult{
+		DebtName:          input.DebtName,
+		AccruedInterest:   roundTo2Decimals(interest),
+		ToFees:            roundTo2Decimals(toFees),
+		ToInterest:        roundTo2Decimals(toInterest),
+		ToPrincipal:       roundTo2Decimals(toPrincipal),
+		Unapplied:         roundTo2Decimals(remaining),
+		RemainingBalance:  roundTo2Decimals(input.Balance - toPrincipal),
+		RemainingFees:     roundTo2Decimals(input.FeesDue - toFees),
+		RemainingInterest: roundTo2Decimals(interest - toInterest),
+	}
+	result.Explanation = s.generateAllocationExplanation(input.Payment, result)
+
+	return result, nil
+}
+
+// accruedInterest calcula el interés devengado desde el último pago según la convención
+func accruedInterest(input domain.PaymentAllocationInput) (float64, error) {
+	annualRate := input.InterestRate / 100
+
+	switch input.AccrualConvention {
+	case "", "monthly":
+		return input.Balance * annualRate / 12, nil
+	case "daily":
+		days := input.DaysSinceLastPayment
+		if days == 0 {
+			days = defaultAccrualDays
+		}
+		if days < 0 || days > MaxAccrualDays {
+			return 0, validationError("INVALID_ACCRUAL_DAYS", MaxAccrualDays)
+		}
+		return input.Balance * annualRate / 365 * float64(days), nil
+	}
+	return 0, validationError("INVALID_DAY_COUNT_CONVENTION")
+}
+
+func (s *PaymentAllocationService) generateAllocationExplanation(
+	payment float64,
+	result domain.PaymentAllocationResult,
+) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("De tu pago de %s, ", formatCurrency(payment)))
+	if result.ToFees > 0 {
+		builder.WriteString(fmt.Sprintf("%s cubren comisiones pendientes, ", formatCurrency(result.ToFees)))
+	}
+	builder.WriteString(fmt.Sprintf("%s se aplican a intereses y %s reducen tu capital. ",
+		formatCurrency(result.ToInterest), formatCurrency(result.ToPrincipal)))
+	builder.WriteString(fmt.Sprintf("Tu nuevo saldo será %s.", formatCurrency(result.RemainingBalance)))
+
+	if result.RemainingInterest > 0 {
+		builder.WriteString(fmt.Sprintf(" El pago no alcanza a cubrir el interés del periodo; quedan %s de intereses pendientes.",
+			formatCurrency(result.RemainingInterest)))
+	}
+	if result.Unapplied > 0 {
+		builder.WriteString(fmt.Sprintf(" Sobran %s que exceden lo adeudado.", formatCurrency(result.Unapplied)))
+	}
+
+	return builder.String()
+}
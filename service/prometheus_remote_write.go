@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// PrometheusRemoteWriter envía periódicamente las métricas de negocio de la
+// hora en curso (UTC) a un endpoint de Prometheus remote-write
+type PrometheusRemoteWriter struct {
+	analytics *AnalyticsService
+	url       string
+	interval  time.Duration
+	client    *http.Client
+	stop      chan struct{}
+}
+
+func NewPrometheusRemoteWriter(
+	analytics *AnalyticsService,
+	url string,
+	interval time.Duration,
+) *PrometheusRemoteWriter {
+	return &PrometheusRemoteWriter{
+		analytics: analytics,
+		url:       url,
+		interval:  interval,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		stop:      make(chan struct{}),
+	}
+}
+
+func (p *PrometheusRemoteWriter) Start() {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := p.Push(); err != nil {
+					slog.Warn("failed to push metrics via remote-write", "error", err)
+				}
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (p *PrometheusRemoteWriter) Stop() {
+	close(p.stop)
+}
+
+type promSeries struct {
+	labels [][2]string // pares nombre/valor; __name__ primero
+	value  float64
+}
+
+// Push envía un sample por métrica con los totales de la hora en curso. La
+// analítica se guarda en buckets por hora, así que no hay una ventana móvil de
+// 60 minutos: las series son contadores por bucket que vuelven a cero al
+// empezar cada hora (sufijo _current_hour)
+func (p *PrometheusRemoteWriter) Push() error {
+	now := time.Now()
+	hour := now.UTC().Truncate(time.Hour)
+	overview, err := p.analytics.Overview(hour, hour.Add(time.Hour), "hour")
+	if err != nil {
+		return err
+	}
+	totals := overview.Totals
+
+	series := []promSeries{
+		{labels: [][2]string{{"__name__", "loan_agent_requests_current_hour"}}, value: float64(totals.TotalRequests)},
+		{labels: [][2]string{{"__name__", "loan_agent_errors_current_hour"}}, value: float64(totals.Errors)},
+		{labels: [][2]string{{"__name__", "loan_agent_rate_limit_rejections_current_hour"}}, value: float64(totals.RateLimitRejections)},
+		{labels: [][2]string{{"__name__", "loan_agent_loans_current_hour"}}, value: float64(totals.LoanCount)},
+		{labels: [][2]string{{"__name__", "loan_agent_average_loan_amount_current_hour"}}, value: totals.AverageLoanAmount},
+	}
+	for _, endpoint := range sortedKeys(totals.Requests) {
+		series = append(series, promSeries{
+			labels: [][2]string{{"__name__", "loan_agent_endpoint_requests_current_hour"}, {"endpoint", endpoint}},
+			value:  float64(totals.Requests[endpoint]),
+		})
+	}
+	for _, strategy := range sortedKeys(totals.StrategyMix) {
+		series = append(series, promSeries{
+			labels: [][2]string{{"__name__", "loan_agent_strategy_requests_current_hour"}, {"strategy", strategy}},
+			value:  float64(totals.StrategyMix[strategy]),
+		})
+	}
+
+	body := snappyEncode(encodeWriteRequest(series, now.UnixMilli()))
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("remote-write respondió %s", resp.Status)
+	}
+	return nil
+}
+
+// encodeWriteRequest codifica un prometheus.WriteRequest en protobuf:
+// WriteRequest{timeseries=1}, TimeSeries{labels=1, samples=2},
+// Label{name=1, value=2}, Sample{value=1 (double), timestamp=2 (int64)}
+func encodeWriteRequest(series []promSeries, timestampMs int64) []byte {
+	var request []byte
+	for _, s := range series {
+		labels := make([][2]string, len(s.labels))
+		copy(labels, s.labels)
+		// Prometheus exige las etiquetas ordenadas por nombre
+		sort.Slice(labels, func(i, j int) bool { return labels[i][0] < labels[j][0] })
+
+		var ts []byte
+		for _, label := range labels {
+			var l []byte
+			l = appendProtoBytes(l, 1, []byte(label[0]))
+			l = appendProtoBytes(l, 2, []byte(label[1]))
+			ts = appendProtoBytes(ts, 1, l)
+		}
+
+		var sample []byte
+		sample = binary.AppendUvarint(sample, 1<<3|1)
+		sample = binary.LittleEndian.AppendUint64(sample, math.Float64bits(s.value))
+		sample = binary.AppendUvarint(sample, 2<<3|0)
+		sample = binary.AppendUvarint(sample, uint64(timestampMs))
+		ts = appendProtoBytes(ts, 2, sample)
+
+		request = appendProtoBytes(request, 1, ts)
+	}
+	return request
+}
+
+func appendProtoBytes(buf []byte, field int, value []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3|2)
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+// snappyEncode genera un bloque snappy válido usando solo literales; los
+// payloads son pequeños y así se evita una dependencia externa
+func snappyEncode(src []byte) []byte {
+	dst := binary.AppendUvarint(nil, uint64(len(src)))
+	for len(src) > 0 {
+		chunk := src
+		if len(chunk) > 65536 {
+			chunk = chunk[:65536]
+		}
+		n := len(chunk) - 1
+		switch {
+		case n < 60:
+			dst = append(dst, byte(n)<<2)
+		case n < 1<<8:
+			dst = append(dst, 60<<2, byte(n))
+		default:
+			dst = append(dst, 61<<2, byte(n), byte(n>>8))
+		}
+		dst = append(dst, chunk...)
+		src = src[len(chunk):]
+	}
+	return dst
+}
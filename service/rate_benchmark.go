@@ -0,0 +1,46 @@
+package service
+
+import (
+	"log/slog"
+	"sort"
+
+	"loan-agent/domain"
+)
+
+// rateBenchmark compara la tasa con las tasas de los cálculos guardados. Sin
+// al menos MinBenchmarkSamples cálculos devuelve nil: una mediana con pocas
+// muestras no es representativa y podría revelar cálculos individuales.
+func (s *LoanService) rateBenchmark(rate float64, language string) *domain.RateBenchmark {
+	rates, err := s.repo.InterestRates()
+	if err != nil {
+		slog.Warn("failed to load interest rates for benchmark", "error", err)
+		return nil
+	}
+	if len(rates) < MinBenchmarkSamples {
+		return nil
+	}
+	sort.Float64s(rates)
+
+	median := rates[len(rates)/2]
+	if len(rates)%2 == 0 {
+		median = (rates[len(rates)/2-1] + rates[len(rates)/2]) / 2
+	}
+	lower := sort.SearchFloat64s(rates, rate)
+
+	position := "at_median"
+	if rate > median+BenchmarkMedianBandRate {
+		position = "above"
+	} else if rate < median-BenchmarkMedianBandRate {
+		position = "below"
+	}
+
+	opts := explanationOptions{Language: language}
+	return &domain.RateBenchmark{
+		Rate:       rate,
+		MedianRate: roundTo2Decimals(median),
+		Percentile: roundTo2Decimals(float64(lower) / float64(len(rates)) * 100),
+		SampleSize: len(rates),
+		Position:   position,
+		Message:    opts.text("benchmark."+position, rate, median, len(rates)),
+	}
+}
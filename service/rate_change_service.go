@@ -0,0 +1,102 @@
+package service
+
+import (
+	"math"
+
+	"loan-agent/domain"
+)
+
+type RateChangeService struct{}
+
+func NewRateChangeService() *RateChangeService {
+	return &RateChangeService{}
+}
+
+// CompareRateChange recalcula la cuota y los intereses del saldo pendiente con
+// la nueva tasa y los compara contra la tasa vigente
+func (s *RateChangeService) CompareRateChange(
+	input domain.RateChangeInput,
+) (domain.RateChangeResult, error) {
+
+	var v fieldValidator
+	switch {
+	case input.RemainingBalance <= 0:
+		v.add("RemainingBalance", validationError("INVALID_OUTSTANDING_BALANCE"))
+	case input.RemainingBalance > MaxLoanAmount:
+		v.add("RemainingBalance", validationError("BALANCE_TOO_LARGE", MaxLoanAmount))
+	}
+	if input.RemainingTermMonths < MinTermMonths || input.RemainingTermMonths > MaxTermMonths {
+		v.add("RemainingTermMonths", validationError("INVALID_REMAINING_TERM", MinTermMonths, MaxTermMonths))
+	}
+	for _, rate := range []struct {
+		field string
+		value float64
+	}{{"CurrentRate", input.CurrentRate}, {"NewRate", input.NewRate}} {
+		switch {
+		case rate.value < 0:
+			v.add(rate.field, validationError("INVALID_INTEREST_RATE"))
+		case rate.value > MaxInterestRate:
+			v.add(rate.field, validationError("INTEREST_RATE_TOO_HIGH", MaxInterestRate))
+		}
+	}
+	validateInsurances(&v, input.Insurances)
+	language, err := validateLanguage(input.Language)
+	v.check("Language", err)
+	if err := v.err(); err != nil {
+		return domain.RateChangeResult{}, err
+	}
+
+	oldLoan := domain.LoanInput{
+		Amount:       input.RemainingBalance,
+		InterestRate: input.CurrentRate,
+		TermMonths:   input.RemainingTermMonths,
+		Insurances:   input.Insurances,
+	}
+	newLoan := oldLoan
+	newLoan.InterestRate = input.NewRate
+
+	oldPayment := monthlyPayment(oldLoan.Amount, oldLoan.InterestRate, oldLoan.TermMonths)
+	newPayment := monthlyPayment(newLoan.Amount, newLoan.InterestRate, newLoan.TermMonths)
+	oldInsurance, _ := insurancePremiums(oldLoan, oldPayment)
+	newSchedule, _ := buildAmortizationSchedule(newLoan, newPayment)
+
+	oldInterest := oldPayment*float64(oldLoan.TermMonths) - oldLoan.Amount
+	newInterest := newPayment*float64(newLoan.TermMonths) - newLoan.Amount
+
+	// Las cuotas reportadas son las que paga el cliente: incluyen el seguro del primer mes
+	oldTotal := oldPayment + oldInsurance
+	newTotal := newPayment + newSchedule[0].Insurance
+
+	result := domain.RateChangeResult{
+		OldMonthlyPayment: roundTo2Decimals(oldTotal),
+		NewMonthlyPayment: roundTo2Decimals(newTotal),
+		PaymentDelta:      roundTo2Decimals(newTotal - oldTotal),
+		OldTotalInterest:  roundTo2Decimals(oldInterest),
+		NewTotalInterest:  roundTo2Decimals(newInterest),
+		InterestDelta:     roundTo2Decimals(newInterest - oldInterest),
+		Schedule:          newSchedule,
+	}
+	result.Explanation = generateRateChangeExplanation(explanationOptions{Language: language}, input, result)
+
+	return result, nil
+}
+
+func generateRateChangeExplanation(
+	opts explanationOptions,
+	input domain.RateChangeInput,
+	result domain.RateChangeResult,
+) string {
+	if result.PaymentDelta == 0 {
+		return opts.text("rate_change.same",
+			input.NewRate, formatCurrency(result.NewMonthlyPayment), input.RemainingTermMonths)
+	}
+
+	key := "rate_change.up"
+	if result.PaymentDelta < 0 {
+		key = "rate_change.down"
+	}
+	return opts.text(key,
+		input.CurrentRate, input.NewRate,
+		formatCurrency(result.OldMonthlyPayment), formatCurrency(result.NewMonthlyPayment),
+		formatCurrency(math.Abs(result.InterestDelta)), input.RemainingTermMonths)
+}
@@ -0,0 +1,17 @@
+package service
+
+import "context"
+
+type sandboxKey struct{}
+
+// WithSandbox marca el contexto de una request sandbox: el cálculo corre
+// normal, pero no se guarda ni dispara webhooks
+func WithSandbox(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sandboxKey{}, true)
+}
+
+// IsSandbox indica si la request del contexto es sandbox
+func IsSandbox(ctx context.Context) bool {
+	sandbox, _ := ctx.Value(sandboxKey{}).(bool)
+	return sandbox
+}
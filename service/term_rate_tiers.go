@@ -0,0 +1,44 @@
+package service
+
+import (
+	"slices"
+
+	"loan-agent/domain"
+)
+
+// sortedRateTiers valida los tramos de tasa y los devuelve ordenados por plazo
+func sortedRateTiers(tiers []domain.RateTier) ([]domain.RateTier, error) {
+	if len(tiers) > MaxRateTiersPerRequest {
+		return nil, validationError("TOO_MANY_RATE_TIERS", MaxRateTiersPerRequest)
+	}
+
+	sorted := slices.Clone(tiers)
+	slices.SortFunc(sorted, func(a, b domain.RateTier) int {
+		return a.MaxTermMonths - b.MaxTermMonths
+	})
+	for i, tier := range sorted {
+		if tier.MaxTermMonths <= 0 || tier.MaxTermMonths > MaxTermMonths {
+			return nil, validationError("INVALID_RATE_TIER_TERM", tier.MaxTermMonths)
+		}
+		if tier.InterestRate < 0 || tier.InterestRate > MaxInterestRate {
+			return nil, validationError("INVALID_RATE_TIER_RATE", tier.MaxTermMonths)
+		}
+		if i > 0 && sorted[i-1].MaxTermMonths == tier.MaxTermMonths {
+			return nil, validationError("DUPLICATE_RATE_TIER")
+		}
+	}
+
+	return sorted, nil
+}
+
+// rateForTerm devuelve la tasa ofrecida para el plazo: la del primer tramo que
+// lo cubre o, sin tramos, la tasa única de la solicitud
+func rateForTerm(input domain.TermRecommendationInput, term int) float64 {
+	for _, tier := range input.RateTiers {
+		if term <= tier.MaxTermMonths {
+			return tier.InterestRate
+		}
+	}
+
+	return input.InterestRate
+}
@@ -1,14 +1,26 @@
 package service
 
 import (
-	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"sort"
+	"sync"
 
 	"loan-agent/domain"
 )
 
+// preferenceWeights son los pesos de puntuación de cada preferencia
+var preferenceWeights = map[string]domain.ScoringWeights{
+	"minimize_interest": {Interest: 0.6, Payment: 0.2, Term: 0.2},
+	"minimize_payment":  {Interest: 0.2, Payment: 0.6, Term: 0.2},
+	"balanced":          {Interest: 0.4, Payment: 0.4, Term: 0.2},
+	// El plazo más corto que cabe en el presupuesto siempre gana
+	"fastest_payoff": {Term: 1},
+	// Costo total con seguros y cargos, no solo intereses
+	"minimize_total_cost_with_fees": {Cost: 0.6, Payment: 0.2, Term: 0.2},
+}
+
 type TermRecommendationService struct {
 	loanService *LoanService
 }
@@ -24,182 +36,395 @@ func (s *TermRecommendationService) RecommendTerm(
 	input domain.TermRecommendationInput,
 ) (domain.TermRecommendationResult, error) {
 
+	var v fieldValidator
 	if input.Amount <= 0 {
-		return domain.TermRecommendationResult{}, errors.New("monto inválido")
+		v.add("Amount", validationError("INVALID_AMOUNT"))
 	}
 	if input.InterestRate < 0 {
-		return domain.TermRecommendationResult{}, errors.New("tasa inválida")
+		v.add("InterestRate", validationError("INVALID_INTEREST_RATE"))
 	}
-	if input.MinTermMonths <= 0 || input.MaxTermMonths <= 0 {
-		return domain.TermRecommendationResult{}, errors.New("plazos inválidos")
+	if input.MinTermMonths <= 0 {
+		v.add("MinTermMonths", validationError("INVALID_TERM_RANGE"))
 	}
-	if input.MinTermMonths > input.MaxTermMonths {
-		return domain.TermRecommendationResult{}, errors.New("plazo mínimo mayor que máximo")
+	switch {
+	case input.MaxTermMonths <= 0:
+		v.add("MaxTermMonths", validationError("INVALID_TERM_RANGE"))
+	case input.MinTermMonths > input.MaxTermMonths:
+		v.add("MinTermMonths", validationError("MIN_TERM_ABOVE_MAX"))
+	case input.MaxTermMonths > MaxTermMonths:
+		v.add("MaxTermMonths", validationError("MAX_TERM_TOO_LONG", MaxTermMonths))
 	}
-	if input.MaxTermMonths > MaxTermMonths {
-		return domain.TermRecommendationResult{}, fmt.Errorf("plazo máximo excede el límite de %d meses", MaxTermMonths)
+	if input.TermStepMonths < 0 || input.TermStepMonths > MaxTermMonths {
+		v.add("TermStepMonths", validationError("INVALID_TERM_STEP"))
 	}
-	// Validar que el rango no sea demasiado grande para evitar cálculos costosos
-	if input.MaxTermMonths-input.MinTermMonths > MaxTermRangeMonths {
-		return domain.TermRecommendationResult{}, fmt.Errorf("rango de plazos excede el máximo de %d meses", MaxTermRangeMonths)
+	tiers, err := sortedRateTiers(input.RateTiers)
+	v.check("RateTiers", err)
+	var combined domain.Affordability
+	if len(input.Borrowers) > 0 {
+		combined, err = calculateAffordability(input.Borrowers)
+		v.check("Borrowers", err)
 	}
-	if input.MaxMonthlyPayment <= 0 {
-		return domain.TermRecommendationResult{}, errors.New("pago mensual máximo inválido")
+	if input.MonthlyIncome < 0 {
+		v.add("MonthlyIncome", validationError("INVALID_MONTHLY_INCOME"))
+	}
+	if input.MaxPaymentToIncome < 0 || input.MaxPaymentToIncome > 100 {
+		v.add("MaxPaymentToIncome", validationError("INVALID_MAX_PAYMENT_RATIO"))
+	}
+	if _, ok := preferenceWeights[input.Preference]; !ok {
+		v.add("Preference", validationError("INVALID_PREFERENCE"))
+	}
+	if weights := input.ScoringWeights; weights != nil {
+		if weights.Interest < 0 || weights.Payment < 0 || weights.Term < 0 || weights.Cost < 0 {
+			v.add("ScoringWeights", validationError("NEGATIVE_SCORING_WEIGHTS"))
+		} else if math.Abs(weights.Interest+weights.Payment+weights.Term+weights.Cost-1) > ScoringWeightsTolerance {
+			v.add("ScoringWeights", validationError("INVALID_SCORING_WEIGHTS_SUM"))
+		}
+	}
+	validateInsurances(&v, input.Insurances)
+	language, err := validateLanguage(input.Language)
+	v.check("Language", err)
+	if !ReadingLevels[input.ReadingLevel] {
+		v.add("ReadingLevel", validationError("INVALID_READING_LEVEL"))
+	}
+	if err := v.err(); err != nil {
+		return domain.TermRecommendationResult{}, err
 	}
 
-	preferences := map[string]bool{
-		"minimize_interest": true,
-		"minimize_payment":  true,
-		"balanced":          true,
+	if len(tiers) > 0 {
+		input.RateTiers = tiers
+		// Solo se evalúan los plazos que algún tramo cubre
+		lastTier := tiers[len(tiers)-1].MaxTermMonths
+		if lastTier < input.MinTermMonths {
+			return domain.TermRecommendationResult{}, validationError("RATE_TIERS_MISS_MIN_TERM")
+		}
+		input.MaxTermMonths = min(input.MaxTermMonths, lastTier)
 	}
-	if !preferences[input.Preference] {
-		return domain.TermRecommendationResult{}, errors.New("preferencia inválida")
+	termStep := max(input.TermStepMonths, 1)
+	// Validar que el rango no tenga demasiados plazos para evitar cálculos costosos
+	if (input.MaxTermMonths-input.MinTermMonths)/termStep > MaxTermRangeMonths {
+		return domain.TermRecommendationResult{}, validationError("TERM_RANGE_TOO_LARGE", MaxTermRangeMonths)
 	}
 
+	// Con deudores, la capacidad combinada limita el pago mensual máximo;
+	// si no se indicó un máximo, la capacidad lo define
+	var affordability *domain.Affordability
+	if len(input.Borrowers) > 0 {
+		affordability = &combined
+		if input.MaxMonthlyPayment <= 0 || combined.MonthlyCapacity < input.MaxMonthlyPayment {
+			input.MaxMonthlyPayment = combined.MonthlyCapacity
+		}
+	}
+	// Con ingreso, la cuota no puede superar la relación cuota/ingreso máxima
+	income, obligations := 0.0, 0.0
+	if input.MonthlyIncome > 0 {
+		income = input.MonthlyIncome
+		maxRatio := input.MaxPaymentToIncome
+		if maxRatio == 0 {
+			maxRatio = GetMaxDebtToIncome()
+		}
+		capacity := income * maxRatio / 100
+		if input.MaxMonthlyPayment <= 0 || capacity < input.MaxMonthlyPayment {
+			input.MaxMonthlyPayment = capacity
+		}
+	} else if affordability != nil {
+		income = affordability.QualifyingIncome
+		obligations = affordability.ExistingObligations
+	}
+	if input.MaxMonthlyPayment <= 0 {
+		return domain.TermRecommendationResult{}, validationError("INVALID_MAX_MONTHLY_PAYMENT")
+	}
+
+	suggestions := suggestTermCorrection("MaxTermMonths", input.MaxTermMonths)
+	if len(input.RateTiers) > 0 {
+		for i, tier := range input.RateTiers {
+			suggestions = append(suggestions, suggestRateCorrection(fmt.Sprintf("RateTiers[%d].InterestRate", i), tier.InterestRate)...)
+		}
+	} else {
+		suggestions = append(suggestions, suggestRateCorrection("InterestRate", input.InterestRate)...)
+	}
+	suggestions = append(suggestions, suggestAmountCorrection("Amount", input.Amount, input.MaxTermMonths)...)
+
 	recommendations := []domain.TermRecommendation{}
 
-	// Calcular escenarios para cada plazo
-	for term := input.MinTermMonths; term <= input.MaxTermMonths; term++ {
-		loanInput := domain.LoanInput{
-			Amount:       input.Amount,
-			InterestRate: input.InterestRate,
-			TermMonths:   term,
+	terms := []int{}
+	for term := input.MinTermMonths; term <= input.MaxTermMonths; term += termStep {
+		terms = append(terms, term)
+	}
+	evaluations := s.evaluateTerms(input, terms)
+
+	// Rango de costo total entre los plazos calculados, para normalizar su score
+	minCost, maxCost := math.Inf(1), math.Inf(-1)
+	for _, evaluation := range evaluations {
+		if evaluation.err == nil {
+			minCost = math.Min(minCost, loanTotalCost(evaluation.result))
+			maxCost = math.Max(maxCost, loanTotalCost(evaluation.result))
 		}
+	}
 
-		result, err := s.loanService.CalculateLoan(loanInput)
+	// Calcular escenarios para cada plazo, en el orden de los plazos
+	messages := explanationOptions{Language: language}
+	rejected := []domain.RejectedTerm{}
+	for i, term := range terms {
+		result, err := evaluations[i].result, evaluations[i].err
 		if err != nil {
-			log.Printf("Warning: failed to calculate loan for term %d: %v", term, err)
+			slog.Warn("failed to calculate loan for term", "term_months", term, "error", err)
+			rejected = append(rejected, domain.RejectedTerm{
+				TermMonths: term,
+				Reason:     messages.text("term.rejected.error", LocalizedMessage(err, language)),
+			})
 			continue
 		}
 
 		// Filtrar por pago mensual máximo
-		if result.MonthlyPayment > input.MaxMonthlyPayment {
+		payment := loanInstallment(result)
+		if payment > input.MaxMonthlyPayment {
+			rejected = append(rejected, domain.RejectedTerm{
+				TermMonths:     term,
+				MonthlyPayment: payment,
+				Reason: messages.text("term.rejected.payment",
+					formatCurrency(payment), formatCurrency(input.MaxMonthlyPayment)),
+			})
 			continue
 		}
 
 		// Calcular score según preferencia
-		score := s.calculateScore(result, input, term)
-		reason := s.generateReason(input)
+		score, breakdown := s.calculateScore(result, input, term, minCost, maxCost)
+		reason := s.generateReason(messages, input.Preference)
 
-		recommendations = append(recommendations, domain.TermRecommendation{
+		recommendation := domain.TermRecommendation{
 			TermMonths:     term,
-			MonthlyPayment: result.MonthlyPayment,
+			InterestRate:   rateForTerm(input, term),
+			MonthlyPayment: payment,
 			TotalInterest:  result.TotalInterest,
+			TotalInsurance: result.TotalInsurance,
+			TotalCost:      loanTotalCost(result),
 			Score:          score,
+			ScoreBreakdown: breakdown,
 			Reason:         reason,
-		})
+		}
+		if income > 0 {
+			recommendation.DebtToIncome = roundTo2Decimals((payment + obligations) / income * 100)
+		}
+		recommendations = append(recommendations, recommendation)
 	}
 
-	// Ordenar por score descendente
-	sort.Slice(recommendations, func(i, j int) bool {
+	// Ordenar por score descendente; los empates conservan el orden de los plazos
+	sort.SliceStable(recommendations, func(i, j int) bool {
 		return recommendations[i].Score > recommendations[j].Score
 	})
 
 	if len(recommendations) == 0 {
-		return domain.TermRecommendationResult{}, errors.New("no se encontraron plazos válidos con el pago mensual máximo especificado")
+		var err error = validationError("NO_AFFORDABLE_TERM")
+		// Indicar la cuota más baja posible para que el usuario sepa cuánto le falta
+		if lowest, ok := lowestRejectedPayment(rejected); ok {
+			err = wrapError(err, "term.lowest_payment", formatCurrency(lowest.MonthlyPayment), lowest.TermMonths)
+		}
+		return domain.TermRecommendationResult{}, withSuggestions(err, suggestions)
+	}
+	if !input.IncludeRejected {
+		rejected = nil
 	}
 
 	recommendedTerm := recommendations[0].TermMonths
+	var benchmark *domain.RateBenchmark
+	if input.IncludeBenchmark {
+		benchmark = s.loanService.rateBenchmark(recommendations[0].InterestRate, language)
+	}
+	markParetoFrontier(recommendations)
 
-	// Generar explicaciones para todas las recomendaciones
-	for i := range recommendations {
-		if i == 0 {
-			recommendations[i].Reason = s.generateTermExplanation(
-				input.Amount,
-				recommendations[i].TermMonths,
-				recommendations[i].MonthlyPayment,
-				recommendations[i].TotalInterest,
-				input.Preference,
-			)
-		} else {
-			recommendations[i].Reason = s.generateTermExplanation(
-				input.Amount,
-				recommendations[i].TermMonths,
-				recommendations[i].MonthlyPayment,
-				recommendations[i].TotalInterest,
-				input.Preference,
-			)
+	// Generar explicaciones para todas las recomendaciones, salvo que se pidan
+	// solo los resultados numéricos
+	if explanationRequested(input.IncludeExplanation) {
+		for i := range recommendations {
+			explain := func(lang string) string {
+				return s.generateTermExplanation(
+					explanationOptions{Language: lang, ReadingLevel: input.ReadingLevel},
+					recommendations[i].TermMonths,
+					recommendations[i].MonthlyPayment,
+					recommendations[i].TotalInterest,
+					recommendations[i].TotalCost,
+					input.Preference,
+				)
+			}
+			recommendations[i].Reason = explain(language)
+			if input.BilingualExplanation {
+				recommendations[i].Reasons = map[string]string{}
+				for _, lang := range SupportedLanguages {
+					recommendations[i].Reasons[lang] = explain(lang)
+				}
+			}
 		}
 	}
 
 	return domain.TermRecommendationResult{
 		RecommendedTerm: recommendedTerm,
 		Recommendations: recommendations,
+		Sensitivity:     rateSensitivity(input, recommendations[0]),
+		Rejected:        rejected,
+		Affordability:   affordability,
+		Suggestions:     localizeSuggestions(suggestions, language),
+		Benchmark:       benchmark,
 	}, nil
 }
 
+type termEvaluation struct {
+	result domain.LoanResult
+	err    error
+}
+
+// evaluateTerms calcula el préstamo de cada plazo con un pool acotado de
+// workers; el resultado i corresponde a terms[i]
+func (s *TermRecommendationService) evaluateTerms(
+	input domain.TermRecommendationInput,
+	terms []int,
+) []termEvaluation {
+	evaluations := make([]termEvaluation, len(terms))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(TermEvaluationWorkers, len(terms)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := s.loanService.quoteLoan(domain.LoanInput{
+					Amount:       input.Amount,
+					InterestRate: rateForTerm(input, terms[i]),
+					TermMonths:   terms[i],
+					Insurances:   input.Insurances,
+				})
+				evaluations[i] = termEvaluation{result: result, err: err}
+			}
+		}()
+	}
+	for i := range terms {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return evaluations
+}
+
+// loanInstallment es la cuota que paga el cliente: capital, interés y la prima
+// de seguros del primer mes
+func loanInstallment(result domain.LoanResult) float64 {
+	return roundTo2Decimals(result.MonthlyPayment + result.MonthlyInsurance)
+}
+
+// loanTotalCost es el total a pagar, con seguros
+func loanTotalCost(result domain.LoanResult) float64 {
+	return roundTo2Decimals(result.TotalPayment + result.TotalInsurance)
+}
+
+// lowestRejectedPayment devuelve el plazo descartado con la menor cuota calculada
+func lowestRejectedPayment(rejected []domain.RejectedTerm) (domain.RejectedTerm, bool) {
+	var lowest domain.RejectedTerm
+	found := false
+	for _, term := range rejected {
+		if term.MonthlyPayment > 0 && (!found || term.MonthlyPayment < lowest.MonthlyPayment) {
+			lowest, found = term, true
+		}
+	}
+
+	return lowest, found
+}
+
+// markParetoFrontier marca los plazos de la frontera eficiente entre cuota y
+// costo total, que la UI puede mostrar en lugar de un único score
+func markParetoFrontier(recommendations []domain.TermRecommendation) {
+	for i := range recommendations {
+		recommendations[i].ParetoOptimal = true
+		for _, other := range recommendations {
+			if other.MonthlyPayment < recommendations[i].MonthlyPayment &&
+				other.TotalCost < recommendations[i].TotalCost {
+				recommendations[i].ParetoOptimal = false
+				break
+			}
+		}
+	}
+}
+
 func (s *TermRecommendationService) calculateScore(
 	result domain.LoanResult,
 	input domain.TermRecommendationInput,
 	term int,
-) float64 {
-	var score float64
-
+	minCost, maxCost float64,
+) (float64, domain.ScoreBreakdown) {
 	// Normalizar valores para scoring (0-10)
-	maxPossibleInterest := input.Amount * (input.InterestRate / 100) * float64(input.MaxTermMonths) / 12
-	minPossibleInterest := input.Amount * (input.InterestRate / 100) * float64(input.MinTermMonths) / 12
+	maxPossibleInterest := input.Amount * (rateForTerm(input, input.MaxTermMonths) / 100) * float64(input.MaxTermMonths) / 12
+	minPossibleInterest := input.Amount * (rateForTerm(input, input.MinTermMonths) / 100) * float64(input.MinTermMonths) / 12
 
 	interestRange := maxPossibleInterest - minPossibleInterest
 	paymentRange := input.MaxMonthlyPayment - (input.Amount / float64(input.MaxTermMonths))
+	termRange := input.MaxTermMonths - input.MinTermMonths
 
-	interestScore := 0.0
-	paymentScore := 0.0
-	termScore := 0.0
+	breakdown := domain.ScoreBreakdown{}
 
 	if interestRange > 0 {
-		interestScore = 10.0 * (1.0 - (result.TotalInterest-minPossibleInterest)/interestRange)
+		breakdown.InterestScore = 10.0 * (1.0 - (result.TotalInterest-minPossibleInterest)/interestRange)
 	}
 	if paymentRange > 0 {
-		paymentScore = 10.0 * (1.0 - (result.MonthlyPayment-input.Amount/float64(input.MaxTermMonths))/paymentRange)
+		breakdown.PaymentScore = 10.0 * (1.0 - (loanInstallment(result)-input.Amount/float64(input.MaxTermMonths))/paymentRange)
+	}
+	if termRange > 0 {
+		breakdown.TermScore = 10.0 * (1.0 - float64(term-input.MinTermMonths)/float64(termRange))
+	}
+	if maxCost > minCost {
+		breakdown.CostScore = 10.0 * (1.0 - (loanTotalCost(result)-minCost)/(maxCost-minCost))
+	}
+	// Con seguros el interés ya no es todo el costo: un plazo más largo paga
+	// más primas, así que el componente de interés puntúa el costo total
+	if len(input.Insurances) > 0 {
+		breakdown.InterestScore = breakdown.CostScore
 	}
-	termScore = 10.0 * (1.0 - float64(term-input.MinTermMonths)/float64(input.MaxTermMonths-input.MinTermMonths))
 
-	switch input.Preference {
-	case "minimize_interest":
-		score = 0.6*interestScore + 0.2*paymentScore + 0.2*termScore
-	case "minimize_payment":
-		score = 0.2*interestScore + 0.6*paymentScore + 0.2*termScore
-	case "balanced":
-		score = 0.4*interestScore + 0.4*paymentScore + 0.2*termScore
+	if input.ScoringWeights != nil {
+		breakdown.Weights = *input.ScoringWeights
+	} else {
+		breakdown.Weights = preferenceWeights[input.Preference]
 	}
+	weights := breakdown.Weights
+	score := weights.Interest*breakdown.InterestScore + weights.Payment*breakdown.PaymentScore +
+		weights.Term*breakdown.TermScore + weights.Cost*breakdown.CostScore
+
+	breakdown.InterestScore = roundTo2Decimals(breakdown.InterestScore)
+	breakdown.PaymentScore = roundTo2Decimals(breakdown.PaymentScore)
+	breakdown.TermScore = roundTo2Decimals(breakdown.TermScore)
+	breakdown.CostScore = roundTo2Decimals(breakdown.CostScore)
 
-	return roundTo2Decimals(score)
+	return roundTo2Decimals(score), breakdown
 }
 
 func (s *TermRecommendationService) generateReason(
-	input domain.TermRecommendationInput,
+	opts explanationOptions,
+	preference string,
 ) string {
-	switch input.Preference {
-	case "minimize_interest":
-		return "Plazo optimizado para minimizar el costo total de intereses"
-	case "minimize_payment":
-		return "Plazo optimizado para minimizar el pago mensual"
-	case "balanced":
-		return "Balance óptimo entre pago mensual y costo total"
-	}
-	return "Recomendación basada en los parámetros proporcionados"
+	return opts.text("term.reason." + preference)
 }
 
 func (s *TermRecommendationService) generateTermExplanation(
-	amount float64,
+	opts explanationOptions,
 	term int,
-	monthlyPayment, totalInterest float64,
+	monthlyPayment, totalInterest, totalCost float64,
 	preference string,
 ) string {
-	totalCost := amount + totalInterest
 	totalInterestFormatted := formatCurrency(totalInterest)
 	monthlyPaymentFormatted := formatCurrency(monthlyPayment)
 	totalCostFormatted := formatCurrency(totalCost)
 
 	switch preference {
 	case "minimize_interest":
-		return fmt.Sprintf("Este plazo de %d meses minimiza el costo total de intereses (%s), aunque requiere una cuota mensual de %s. El costo total del préstamo será %s. Esta opción es ideal si tu prioridad es reducir el costo financiero total en el mercado crediticio nicaragüense.",
+		return opts.text("term.minimize_interest",
 			term, totalInterestFormatted, monthlyPaymentFormatted, totalCostFormatted)
 	case "minimize_payment":
-		return fmt.Sprintf("Este plazo de %d meses minimiza tu cuota mensual a %s, proporcionando mayor flexibilidad presupuestaria. Pagarás %s en intereses para un costo total de %s. Ideal para préstamos personales cuando necesitas maximizar tu capacidad de pago mensual.",
+		return opts.text("term.minimize_payment",
+			term, monthlyPaymentFormatted, totalInterestFormatted, totalCostFormatted)
+	case "fastest_payoff", "minimize_total_cost_with_fees":
+		return opts.text("term."+preference,
 			term, monthlyPaymentFormatted, totalInterestFormatted, totalCostFormatted)
 	default:
-		return fmt.Sprintf("Este plazo de %d meses ofrece un balance óptimo entre cuota mensual (%s) y costo total de intereses (%s). El costo total del préstamo será %s. Esta recomendación equilibra tu capacidad de pago mensual con el costo financiero total en el contexto nicaragüense.",
+		return opts.text("term.balanced",
 			term, monthlyPaymentFormatted, totalInterestFormatted, totalCostFormatted)
 	}
 }
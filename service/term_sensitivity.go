@@ -0,0 +1,46 @@
+package service
+
+import "loan-agent/domain"
+
+// SensitivityRateChanges son los movimientos de tasa (puntos porcentuales)
+// evaluados sobre el plazo recomendado
+var SensitivityRateChanges = []float64{-2, -1, 1, 2}
+
+// rateSensitivity recalcula la cuota y el costo total de la recomendación con
+// la tasa movida en cada SensitivityRateChanges; los escenarios con tasa
+// negativa se omiten. No guarda los cálculos en el repositorio.
+func rateSensitivity(
+	input domain.TermRecommendationInput,
+	recommendation domain.TermRecommendation,
+) []domain.RateSensitivity {
+	sensitivity := []domain.RateSensitivity{}
+	for _, change := range SensitivityRateChanges {
+		rate := roundTo2Decimals(recommendation.InterestRate + change)
+		if rate < 0 {
+			continue
+		}
+
+		loan := domain.LoanInput{
+			Amount:       input.Amount,
+			InterestRate: rate,
+			TermMonths:   recommendation.TermMonths,
+			Insurances:   input.Insurances,
+		}
+		cuota := monthlyPayment(loan.Amount, loan.InterestRate, loan.TermMonths)
+		primerSeguro, totalSeguro := insurancePremiums(loan, cuota)
+
+		payment := roundTo2Decimals(cuota + primerSeguro)
+		totalCost := roundTo2Decimals(cuota*float64(loan.TermMonths) + totalSeguro)
+
+		sensitivity = append(sensitivity, domain.RateSensitivity{
+			RateChange:     change,
+			InterestRate:   rate,
+			MonthlyPayment: payment,
+			TotalCost:      totalCost,
+			PaymentChange:  roundTo2Decimals(payment - recommendation.MonthlyPayment),
+			CostChange:     roundTo2Decimals(totalCost - recommendation.TotalCost),
+		})
+	}
+
+	return sensitivity
+}